@@ -197,9 +197,13 @@ const (
 	AddonSortGameVersion
 )
 
+// DefaultPageSize is the number of search results fetched in each request
+// when AddonSearchOption.PageSize is unset
+const DefaultPageSize = 50
+
 func setDefaultUnsetOptions(opts *AddonSearchOption) *AddonSearchOption {
 	if opts.PageSize == 0 {
-		opts.PageSize = 9999
+		opts.PageSize = DefaultPageSize
 	}
 	return opts
 }
@@ -214,7 +218,7 @@ func (c *ApiClient) AddonSearch(ctx context.Context, opts AddonSearchOption) (Se
 		return nil, err
 	}
 
-	resp, err := fetchJSON(ctx, c.HttpClient, "GET", queryUrl, nil)
+	resp, err := fetchJSON(ctx, c.HttpClient, "GET", queryUrl, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -229,7 +233,7 @@ func (c *ApiClient) AddonByID(ctx context.Context, id int) (*Addon, error) {
 		return nil, err
 	}
 
-	resp, err := fetchJSON(ctx, c.HttpClient, "GET", queryUrl, nil)
+	resp, err := fetchJSON(ctx, c.HttpClient, "GET", queryUrl, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -16,6 +16,9 @@ const ApiUrl = "https://addons-ecs.forgesvc.net/api"
 
 const GameMinecraft int = 432
 
+// UserAgent identifies mcmod to the APIs it talks to
+const UserAgent = "github.com/frebib/mcmod"
+
 type ApiClient struct {
 	HttpClient *http.Client
 	ApiUrl     string
@@ -26,23 +29,14 @@ var DefaultClient = &ApiClient{
 	ApiUrl:     ApiUrl,
 }
 
-// ClientKey is the textual key used to identify
-// an API Client inside a context.Context object
-const ClientKey = "api-client"
-
-// ClientFromContext loads a configured ApiClient from a context
-// or returns the DefaultClient if none is found in the context
-func ClientFromContext(ctx context.Context) *ApiClient {
-	clientObj := ctx.Value(ClientKey)
-	client, ok := clientObj.(*ApiClient)
-	if ok {
-		return client
-	}
-	return DefaultClient
+var _ Backend = &ApiClient{}
+
+func (c *ApiClient) Name() string {
+	return "forgesvc"
 }
 
 func fetchJSON(ctx context.Context, client *http.Client, method, url string,
-	body io.Reader) (*http.Response, error) {
+	header http.Header, body io.Reader) (*http.Response, error) {
 
 	log := modlog.FromContext(ctx)
 
@@ -55,8 +49,12 @@ func fetchJSON(ctx context.Context, client *http.Client, method, url string,
 
 	log.Tracef("requesting %s %s", method, url)
 
+	for key, values := range header {
+		req.Header[key] = values
+	}
 	// All API responses return JSON
 	req.Header.Add("Content-Type", "application/json")
+	req.Header.Set("User-Agent", UserAgent)
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
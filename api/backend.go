@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sort"
+)
+
+// Backend is a source of mods, such as CurseForge. Each implementation maps
+// its own representation of projects and files onto Addon and File so that the
+// commands can operate on any source interchangeably
+type Backend interface {
+	// Name returns the name that the backend is registered with
+	Name() string
+	// AddonSearch returns the mods matching the search options
+	AddonSearch(ctx context.Context, opts AddonSearchOption) (SearchResult, error)
+	// Lookup finds a single mod by its name, slug or id
+	Lookup(ctx context.Context, modNameId string) (*Addon, error)
+	// AddonByID fetches the metadata of a single mod by its id
+	AddonByID(ctx context.Context, id int) (*Addon, error)
+	// Files lists every file that has been published for a mod
+	Files(ctx context.Context, mod int) (Files, error)
+	// Dependencies resolves the mods that a file depends upon
+	Dependencies(ctx context.Context, file *File) ([]Dependency, error)
+}
+
+// FingerprintMatcher is implemented by backends that can identify files from
+// their fingerprint, as calculated by the fingerprint package
+type FingerprintMatcher interface {
+	FingerprintMatch(ctx context.Context, fingerprints []int64) (*FingerprintMatches, error)
+}
+
+// BackendKey is the textual key used to identify
+// a Backend inside a context.Context object
+const BackendKey = "api-backend"
+
+// DefaultBackend is the name of the Backend used when none is specified
+const DefaultBackend = "curseforge"
+
+// BackendConfig holds the user settings needed to construct a Backend
+type BackendConfig struct {
+	// HttpClient is shared by all requests made by the Backend
+	HttpClient       *http.Client
+	CurseForgeApiKey string
+}
+
+var backends = map[string]func(*BackendConfig) Backend{
+	"curseforge": func(conf *BackendConfig) Backend {
+		client := *DefaultCurseForgeClient
+		client.HttpClient = conf.HttpClient
+		client.ApiKey = conf.CurseForgeApiKey
+		return &client
+	},
+	// forgesvc is the retired CurseForge v2 API, kept for any mirrors of it
+	"forgesvc": func(conf *BackendConfig) Backend {
+		client := *DefaultClient
+		client.HttpClient = conf.HttpClient
+		return &client
+	},
+	"modrinth": func(conf *BackendConfig) Backend {
+		client := *DefaultModrinthClient
+		client.HttpClient = conf.HttpClient
+		return &client
+	},
+}
+
+// BackendNames lists the names of all known backends, in alphabetical order
+func BackendNames() []string {
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewBackend returns the Backend registered with the given name, configured
+// with the given settings
+func NewBackend(name string, conf *BackendConfig) (Backend, error) {
+	newFunc, ok := backends[name]
+	if !ok {
+		return nil, &ErrUnknownBackend{name}
+	}
+	var backendConf BackendConfig
+	if conf != nil {
+		backendConf = *conf
+	}
+	if backendConf.HttpClient == nil {
+		backendConf.HttpClient = new(http.Client)
+	}
+	return newFunc(&backendConf), nil
+}
+
+// BackendFromContext loads a configured Backend from a context
+// or returns the DefaultCurseForgeClient if none is found in the context
+func BackendFromContext(ctx context.Context) Backend {
+	backendObj := ctx.Value(BackendKey)
+	backend, ok := backendObj.(Backend)
+	if ok {
+		return backend
+	}
+	return DefaultCurseForgeClient
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+	"time"
+
+	modlog "github.com/frebib/mcmod/log"
+	"github.com/frebib/mcmod/util"
+)
+
+// DefaultCacheTTL is how long a cached response is used before revalidating it
+const DefaultCacheTTL = time.Hour
+
+type CacheMode int
+
+const (
+	// CacheEnabled serves fresh responses from the cache, and revalidates
+	// stale responses with the server
+	CacheEnabled CacheMode = iota
+	// CacheRefresh revalidates every cached response with the server
+	CacheRefresh
+	// CacheDisabled neither reads from nor writes to the cache
+	CacheDisabled
+)
+
+// Cache is an on-disk cache of API responses, keyed by the request URL. Stale
+// responses are revalidated using the ETag and Last-Modified headers returned
+// by the server, where present
+type Cache struct {
+	// Dir is the directory that cached responses are stored in
+	Dir  string
+	TTL  time.Duration
+	Mode CacheMode
+}
+
+// CacheKey is the textual key used to identify
+// a Cache inside a context.Context object
+const CacheKey = "api-cache"
+
+// CacheFromContext loads the Cache from a context, or returns nil if none is
+// found in the context
+func CacheFromContext(ctx context.Context) *Cache {
+	cache, _ := ctx.Value(CacheKey).(*Cache)
+	return cache
+}
+
+// DefaultCacheDir returns the directory in the user cache directory that API
+// responses are cached in e.g. ~/.cache/mcmod/http
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "mcmod", "http"), nil
+}
+
+// Transport wraps next, an http.RoundTripper, with the cache. If next is nil
+// then http.DefaultTransport is used
+func (c *Cache) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &cacheTransport{cache: c, next: next}
+}
+
+// Clean removes every cached response, returning the number removed
+func (c *Cache) Clean() (removed int, err error) {
+	entries, err := ioutil.ReadDir(c.Dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	for _, entry := range entries {
+		err = os.Remove(filepath.Join(c.Dir, entry.Name()))
+		if err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+func (c *Cache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:]))
+}
+
+// load reads a cached response for the request, and the time it was stored
+func (c *Cache) load(req *http.Request) (*http.Response, time.Time, error) {
+	path := c.path(req.URL.String())
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), req)
+	return resp, info.ModTime(), err
+}
+
+// store writes the response to the cache, leaving resp.Body readable
+func (c *Cache) store(resp *http.Response) error {
+	data, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		return err
+	}
+	return util.WriteFileAtomic(c.path(resp.Request.URL.String()), data, 0644)
+}
+
+// touch marks a cached response as fresh again after revalidating it
+func (c *Cache) touch(url string) error {
+	now := time.Now()
+	return os.Chtimes(c.path(url), now, now)
+}
+
+type cacheTransport struct {
+	cache *Cache
+	next  http.RoundTripper
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Only idempotent requests can be cached
+	if req.Method != http.MethodGet || t.cache.Mode == CacheDisabled {
+		return t.next.RoundTrip(req)
+	}
+	log := modlog.FromContext(req.Context()).
+		WithField("url", req.URL.String())
+
+	cached, stored, err := t.cache.load(req)
+	if err != nil && !os.IsNotExist(err) {
+		log.WithError(err).Debug("failed to read cached response")
+	}
+	if cached != nil {
+		age := time.Since(stored)
+		if t.cache.Mode != CacheRefresh && age < t.cache.TTL {
+			log.Tracef("using cached response from %s ago", age.Round(time.Second))
+			return cached, nil
+		}
+
+		// Ask the server whether the cached response is still valid
+		req = req.Clone(req.Context())
+		if etag := cached.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if modified := cached.Header.Get("Last-Modified"); modified != "" {
+			req.Header.Set("If-Modified-Since", modified)
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached != nil && resp.StatusCode == http.StatusNotModified {
+		_ = resp.Body.Close()
+		log.Trace("cached response is still valid")
+		err = t.cache.touch(req.URL.String())
+		if err != nil {
+			log.WithError(err).Debug("failed to refresh cached response")
+		}
+		return cached, nil
+	}
+	if cached != nil {
+		_ = cached.Body.Close()
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		err = t.cache.store(resp)
+		if err != nil {
+			log.WithError(err).Debug("failed to cache response")
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	var requests, revalidations int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidations++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{"id": 1}`))
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "mcmod-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cache := &Cache{Dir: dir, TTL: time.Hour}
+	client := &http.Client{Transport: cache.Transport(srv.Client().Transport)}
+	get := func() {
+		t.Helper()
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(body) != `{"id": 1}` {
+			t.Fatalf("unexpected response body: %s", body)
+		}
+	}
+
+	// The second request is served from the cache
+	get()
+	get()
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %d", requests)
+	}
+
+	// Stale responses are revalidated
+	cache.TTL = 0
+	get()
+	if requests != 2 || revalidations != 1 {
+		t.Errorf("expected 1 revalidation, got %d of %d requests", revalidations, requests)
+	}
+
+	cache.Mode = CacheDisabled
+	get()
+	if requests != 3 || revalidations != 1 {
+		t.Errorf("expected uncached request, got %d of %d requests", revalidations, requests)
+	}
+
+	removed, err := cache.Clean()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 {
+		t.Errorf("expected 1 cached response to be removed, got %d", removed)
+	}
+}
@@ -0,0 +1,396 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	mc "github.com/frebib/mcmod/minecraft"
+)
+
+const CurseForgeApiUrl = "https://api.curseforge.com"
+
+// ClassMods is the CurseForge class id of Minecraft mods, as opposed to
+// modpacks, resource packs and worlds
+const ClassMods int = 6
+
+// curseForgeMaxPageSize is the largest page size accepted by the CurseForge
+// API, and curseForgeMaxResults the largest index+pageSize it will serve
+const (
+	curseForgeMaxPageSize = 50
+	curseForgeMaxResults  = 10000
+)
+
+var ErrNoApiKey = errors.New("no CurseForge API key configured")
+
+// CurseForgeClient is a Backend for the official CurseForge v1 API at
+// api.curseforge.com, which requires an API key for every request
+type CurseForgeClient struct {
+	HttpClient *http.Client
+	ApiUrl     string
+	ApiKey     string
+}
+
+var DefaultCurseForgeClient = &CurseForgeClient{
+	HttpClient: new(http.Client),
+	ApiUrl:     CurseForgeApiUrl,
+}
+
+var _ Backend = &CurseForgeClient{}
+var _ FingerprintMatcher = &CurseForgeClient{}
+
+func (c *CurseForgeClient) Name() string {
+	return "curseforge"
+}
+
+// cfResponse is the envelope that wraps every CurseForge v1 response
+type cfResponse struct {
+	Data       json.RawMessage `json:"data"`
+	Pagination *cfPagination   `json:"pagination"`
+}
+
+type cfPagination struct {
+	Index       int `json:"index"`
+	PageSize    int `json:"pageSize"`
+	ResultCount int `json:"resultCount"`
+	TotalCount  int `json:"totalCount"`
+}
+
+// cfMod decodes a v1 mod into an Addon. Fields with the same name decode
+// straight into the Addon, and those that differ are decoded alongside it
+type cfMod struct {
+	Addon
+	Links struct {
+		WebsiteURL string `json:"websiteUrl"`
+	} `json:"links"`
+	MainFileID         int               `json:"mainFileId"`
+	Logo               *AddonAttachment  `json:"logo"`
+	LatestFilesIndexes []cfFileIndex     `json:"latestFilesIndexes"`
+	Screenshots        []AddonAttachment `json:"screenshots"`
+}
+
+type cfFileIndex struct {
+	GameVersion string      `json:"gameVersion"`
+	FileID      int         `json:"fileId"`
+	Filename    string      `json:"filename"`
+	ReleaseType ReleaseType `json:"releaseType"`
+}
+
+// cfFile decodes a v1 file into a File, in the same way as cfMod
+type cfFile struct {
+	File
+	ModID           int            `json:"modId"`
+	GameVersions    []string       `json:"gameVersions"`
+	FileFingerprint int64          `json:"fileFingerprint"`
+	Hashes          []cfHash       `json:"hashes"`
+	Dependencies    []cfDependency `json:"dependencies"`
+	Modules         []cfModule     `json:"modules"`
+}
+
+type cfHash struct {
+	Value string `json:"value"`
+	Algo  int    `json:"algo"`
+}
+
+// cfHashAlgos maps CurseForge hash algorithm ids onto their names
+var cfHashAlgos = map[int]string{
+	1: "sha1",
+	2: "md5",
+}
+
+type cfDependency struct {
+	ModID        int            `json:"modId"`
+	RelationType DependencyType `json:"relationType"`
+}
+
+type cfModule struct {
+	Name        string `json:"name"`
+	Fingerprint int64  `json:"fingerprint"`
+}
+
+type cfFingerprintMatches struct {
+	ExactMatches          []cfFingerprintMatch `json:"exactMatches"`
+	UnmatchedFingerprints []int64              `json:"unmatchedFingerprints"`
+}
+
+type cfFingerprintMatch struct {
+	ID          int      `json:"id"`
+	File        cfFile   `json:"file"`
+	LatestFiles []cfFile `json:"latestFiles"`
+}
+
+// FingerprintMatches is the result of matching file fingerprints against the
+// fingerprints of every file known to CurseForge
+type FingerprintMatches struct {
+	ExactMatches []FingerprintMatch
+	Unmatched    []int64
+}
+
+type FingerprintMatch struct {
+	// ID is the id of the mod the file belongs to
+	ID          int
+	File        File
+	LatestFiles Files
+}
+
+func (c *CurseForgeClient) fetch(ctx context.Context, method, urlPath string,
+	params url.Values, reqBody interface{}) (*cfResponse, error) {
+
+	if c.ApiKey == "" {
+		return nil, ErrNoApiKey
+	}
+
+	queryUrl, err := buildURLParams(c.ApiUrl, urlPath, &params)
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.Reader
+	if reqBody != nil {
+		bodyBytes, err := json.Marshal(reqBody)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(bodyBytes)
+	}
+
+	header := http.Header{"X-Api-Key": {c.ApiKey}}
+	resp, err := fetchJSON(ctx, c.HttpClient, method, queryUrl, header, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var envelope cfResponse
+	return &envelope, json.NewDecoder(resp.Body).Decode(&envelope)
+}
+
+func (c *CurseForgeClient) AddonSearch(ctx context.Context, opts AddonSearchOption) (SearchResult, error) {
+	setDefaultUnsetOptions(&opts)
+
+	// The default sort is by popularity, and the v1 sort fields are offset by
+	// one from those of AddonSortMethod to make room for "featured"
+	sortField := int(opts.Sort) + 1
+	if opts.Sort == AddonSortDefault {
+		sortField = int(AddonSortPopularity) + 1
+	}
+	gameId := opts.GameId
+	if gameId == 0 {
+		gameId = GameMinecraft
+	}
+
+	results := make(SearchResult, 0)
+	for index := opts.Index; len(results) < opts.PageSize; {
+		pageSize := opts.PageSize - len(results)
+		if pageSize > curseForgeMaxPageSize {
+			pageSize = curseForgeMaxPageSize
+		}
+		if index+pageSize > curseForgeMaxResults {
+			break
+		}
+		params := url.Values{
+			"gameId":       {strconv.Itoa(gameId)},
+			"classId":      {strconv.Itoa(ClassMods)},
+			"searchFilter": {opts.Filter},
+			"sortField":    {strconv.Itoa(sortField)},
+			"sortOrder":    {"desc"},
+			"index":        {strconv.Itoa(index)},
+			"pageSize":     {strconv.Itoa(pageSize)},
+		}
+		if opts.CategoryID != 0 {
+			params.Set("categoryId", strconv.Itoa(opts.CategoryID))
+		}
+		if opts.GameVersion != "" {
+			params.Set("gameVersion", opts.GameVersion)
+		}
+		if opts.Slug != "" {
+			params.Set("slug", opts.Slug)
+		}
+
+		resp, err := c.fetch(ctx, "GET", "v1/mods/search", params, nil)
+		if err != nil {
+			return nil, err
+		}
+		var mods []cfMod
+		err = json.Unmarshal(resp.Data, &mods)
+		if err != nil {
+			return nil, err
+		}
+		for _, mod := range mods {
+			results = append(results, *mod.addon())
+		}
+
+		index += len(mods)
+		if len(mods) < 1 || resp.Pagination == nil ||
+			index >= resp.Pagination.TotalCount {
+			break
+		}
+	}
+	return results, nil
+}
+
+func (c *CurseForgeClient) Lookup(ctx context.Context, modNameId string) (*Addon, error) {
+	return lookup(ctx, c, modNameId)
+}
+
+func (c *CurseForgeClient) AddonByID(ctx context.Context, id int) (*Addon, error) {
+	resp, err := c.fetch(ctx, "GET", "v1/mods/"+strconv.Itoa(id), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var mod cfMod
+	err = json.Unmarshal(resp.Data, &mod)
+	if err != nil {
+		return nil, err
+	}
+	return mod.addon(), nil
+}
+
+func (c *CurseForgeClient) Files(ctx context.Context, mod int) (Files, error) {
+	path := fmt.Sprintf("v1/mods/%d/files", mod)
+	files := make(Files, 0)
+	for {
+		params := url.Values{
+			"index":    {strconv.Itoa(len(files))},
+			"pageSize": {strconv.Itoa(curseForgeMaxPageSize)},
+		}
+		resp, err := c.fetch(ctx, "GET", path, params, nil)
+		if err != nil {
+			return nil, err
+		}
+		page, err := decodeCfFiles(resp.Data)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, page...)
+
+		if len(page) < curseForgeMaxPageSize || resp.Pagination == nil ||
+			len(files) >= resp.Pagination.TotalCount {
+			return files, nil
+		}
+	}
+}
+
+// FilesByID fetches the metadata of many files, from any mods, at once
+func (c *CurseForgeClient) FilesByID(ctx context.Context, ids []int) (Files, error) {
+	reqBody := struct {
+		FileIds []int `json:"fileIds"`
+	}{ids}
+	resp, err := c.fetch(ctx, "POST", "v1/mods/files", nil, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	return decodeCfFiles(resp.Data)
+}
+
+// FingerprintMatch finds the files matching each of the given fingerprints
+func (c *CurseForgeClient) FingerprintMatch(ctx context.Context, fingerprints []int64) (*FingerprintMatches, error) {
+	reqBody := struct {
+		Fingerprints []int64 `json:"fingerprints"`
+	}{fingerprints}
+	resp, err := c.fetch(ctx, "POST", "v1/fingerprints", nil, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	var matches cfFingerprintMatches
+	err = json.Unmarshal(resp.Data, &matches)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FingerprintMatches{
+		ExactMatches: make([]FingerprintMatch, len(matches.ExactMatches)),
+		Unmatched:    matches.UnmatchedFingerprints,
+	}
+	for idx, match := range matches.ExactMatches {
+		latest := make(Files, len(match.LatestFiles))
+		for fileIdx, file := range match.LatestFiles {
+			latest[fileIdx] = *file.file()
+		}
+		result.ExactMatches[idx] = FingerprintMatch{
+			ID:          match.ID,
+			File:        *match.File.file(),
+			LatestFiles: latest,
+		}
+	}
+	return result, nil
+}
+
+func (c *CurseForgeClient) Dependencies(ctx context.Context, file *File) ([]Dependency, error) {
+	return file.Dependencies, nil
+}
+
+func decodeCfFiles(data json.RawMessage) (Files, error) {
+	var cfFiles []cfFile
+	err := json.Unmarshal(data, &cfFiles)
+	if err != nil {
+		return nil, err
+	}
+	files := make(Files, len(cfFiles))
+	for idx, file := range cfFiles {
+		files[idx] = *file.file()
+	}
+	return files, nil
+}
+
+func (m *cfMod) addon() *Addon {
+	addon := m.Addon
+	addon.WebsiteURL = m.Links.WebsiteURL
+	addon.DefaultFileID = m.MainFileID
+
+	addon.Attachments = append(make([]AddonAttachment, 0), m.Screenshots...)
+	if m.Logo != nil {
+		logo := *m.Logo
+		logo.IsDefault = true
+		addon.Attachments = append([]AddonAttachment{logo}, addon.Attachments...)
+	}
+
+	addon.GameVersionLatestFiles = make([]AddonGameVersionLatestFile, 0)
+	for _, index := range m.LatestFilesIndexes {
+		// Skip versions that can't be represented, such as April Fools releases
+		ver, err := mc.Parse(index.GameVersion)
+		if err != nil {
+			continue
+		}
+		addon.GameVersionLatestFiles = append(addon.GameVersionLatestFiles,
+			AddonGameVersionLatestFile{
+				GameVersion:     *ver,
+				ProjectFileID:   index.FileID,
+				ProjectFileName: index.Filename,
+				FileType:        int(index.ReleaseType),
+			},
+		)
+	}
+	return &addon
+}
+
+func (f *cfFile) file() *File {
+	file := f.File
+	file.ModID = f.ModID
+	file.GameVersion = f.GameVersions
+	file.PackageFingerprint = f.FileFingerprint
+
+	file.Hashes = make(map[string]string, len(f.Hashes))
+	for _, hash := range f.Hashes {
+		if algo, ok := cfHashAlgos[hash.Algo]; ok {
+			file.Hashes[algo] = hash.Value
+		}
+	}
+
+	file.Dependencies = make([]Dependency, len(f.Dependencies))
+	for idx, dep := range f.Dependencies {
+		file.Dependencies[idx] = Dependency{AddonID: dep.ModID, Type: dep.RelationType}
+	}
+
+	file.Modules = make([]Module, len(f.Modules))
+	for idx, module := range f.Modules {
+		file.Modules[idx] = Module{Foldername: module.Name, Fingerprint: module.Fingerprint}
+	}
+	return &file
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+const testApiKey = "test-key"
+
+func newCurseForgeTestClient(t *testing.T) *CurseForgeClient {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != testApiKey {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/mods/search":
+			// Serve 60 results, split over pages of at most 50
+			index, _ := strconv.Atoi(r.URL.Query().Get("index"))
+			pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+			mods := ""
+			count := 0
+			for id := index; id < 60 && count < pageSize; id++ {
+				if count > 0 {
+					mods += ","
+				}
+				mods += fmt.Sprintf(`{"id": %d, "slug": "mod-%d"}`, id, id)
+				count++
+			}
+			fmt.Fprintf(w, `{"data": [%s], "pagination": {"index": %d,
+				"pageSize": %d, "resultCount": %d, "totalCount": 60}}`,
+				mods, index, pageSize, count)
+		case "/v1/mods/238222":
+			fmt.Fprint(w, `{"data": {
+				"id": 238222, "name": "Just Enough Items (JEI)", "slug": "jei",
+				"links": {"websiteUrl": "https://www.curseforge.com/minecraft/mc-mods/jei"},
+				"mainFileId": 3040523,
+				"latestFilesIndexes": [
+					{"gameVersion": "1.16.5", "fileId": 3040523, "filename": "jei.jar", "releaseType": 1},
+					{"gameVersion": "1.17-Snapshot", "fileId": 3040524, "filename": "jei.jar", "releaseType": 3}
+				]
+			}}`)
+		case "/v1/mods/238222/files":
+			fmt.Fprint(w, `{"data": [{
+				"id": 3040523, "modId": 238222, "fileName": "jei.jar",
+				"fileLength": 1024, "releaseType": 1, "fileFingerprint": 1234,
+				"gameVersions": ["1.16.5", "Forge"],
+				"hashes": [{"value": "da39a3ee", "algo": 1}, {"value": "d41d8cd9", "algo": 2}],
+				"dependencies": [{"modId": 1, "relationType": 3}],
+				"modules": [{"name": "META-INF", "fingerprint": 5678}]
+			}], "pagination": {"index": 0, "pageSize": 50, "resultCount": 1, "totalCount": 1}}`)
+		case "/v1/fingerprints":
+			var req struct{ Fingerprints []int64 }
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			fmt.Fprintf(w, `{"data": {
+				"exactMatches": [{"id": 238222, "file": {"id": 3040523, "modId": 238222,
+					"fileName": "jei.jar", "fileFingerprint": 1234}, "latestFiles": []}],
+				"unmatchedFingerprints": [%d]
+			}}`, req.Fingerprints[len(req.Fingerprints)-1])
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return &CurseForgeClient{HttpClient: srv.Client(), ApiUrl: srv.URL, ApiKey: testApiKey}
+}
+
+func TestCurseForgeSearchPages(t *testing.T) {
+	client := newCurseForgeTestClient(t)
+	results, err := client.AddonSearch(context.Background(), AddonSearchOption{PageSize: 55})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 55 {
+		t.Fatalf("expected 55 results, got %d", len(results))
+	}
+	for idx, addon := range results {
+		if addon.ID != idx {
+			t.Fatalf("unexpected result order: %d at index %d", addon.ID, idx)
+		}
+	}
+}
+
+func TestCurseForgeAddon(t *testing.T) {
+	client := newCurseForgeTestClient(t)
+	addon, err := client.AddonByID(context.Background(), 238222)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addon.Slug != "jei" || addon.DefaultFileID != 3040523 ||
+		addon.WebsiteURL != "https://www.curseforge.com/minecraft/mc-mods/jei" {
+		t.Errorf("unexpected addon: %#v", addon)
+	}
+	if versions := addon.SupportedVersions().Strings(); !reflect.DeepEqual(versions, []string{"1.16.5", "1.17-Snapshot"}) {
+		t.Errorf("unexpected supported versions: %v", versions)
+	}
+}
+
+func TestCurseForgeFiles(t *testing.T) {
+	client := newCurseForgeTestClient(t)
+	files, err := client.Files(context.Background(), 238222)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Files{{
+		ID:                 3040523,
+		ModID:              238222,
+		FileName:           "jei.jar",
+		FileLength:         1024,
+		ReleaseType:        ReleaseRelease,
+		PackageFingerprint: 1234,
+		GameVersion:        []string{"1.16.5", "Forge"},
+		Hashes:             map[string]string{"sha1": "da39a3ee", "md5": "d41d8cd9"},
+		Dependencies:       []Dependency{{AddonID: 1, Type: DependencyRequired}},
+		Modules:            []Module{{Foldername: "META-INF", Fingerprint: 5678}},
+	}}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("unexpected files:\nexpected: %#v\ngot:      %#v", expected, files)
+	}
+}
+
+func TestCurseForgeFingerprintMatch(t *testing.T) {
+	client := newCurseForgeTestClient(t)
+	matches, err := client.FingerprintMatch(context.Background(), []int64{1234, 5678})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches.ExactMatches) != 1 {
+		t.Fatalf("expected 1 match, got %#v", matches.ExactMatches)
+	}
+	match := matches.ExactMatches[0]
+	if match.ID != 238222 || match.File.ID != 3040523 || match.File.PackageFingerprint != 1234 {
+		t.Errorf("unexpected match: %#v", match)
+	}
+	if !reflect.DeepEqual(matches.Unmatched, []int64{5678}) {
+		t.Errorf("expected unmatched [5678], got %v", matches.Unmatched)
+	}
+}
+
+func TestCurseForgeNoApiKey(t *testing.T) {
+	client := newCurseForgeTestClient(t)
+	client.ApiKey = ""
+	_, err := client.AddonByID(context.Background(), 238222)
+	if err != ErrNoApiKey {
+		t.Errorf("expected ErrNoApiKey, got %v", err)
+	}
+}
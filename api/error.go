@@ -34,3 +34,24 @@ func (e *ErrNoSuchAddon) Error() string {
 		return "no mod found matching criteria"
 	}
 }
+
+type ErrUnknownBackend struct {
+	Name string
+}
+
+func (e *ErrUnknownBackend) Error() string {
+	return fmt.Sprintf("unknown backend '%s'", e.Name)
+}
+
+// ErrNoDownloadURL is returned for a file that the backend won't give a
+// download link for, such as when a mod's author has disabled third-party
+// downloads on CurseForge
+type ErrNoDownloadURL struct {
+	ModID    int
+	FileName string
+}
+
+func (e *ErrNoDownloadURL) Error() string {
+	return fmt.Sprintf("mod %d: no download url for '%s', "+
+		"downloads may be disabled by the mod author", e.ModID, e.FileName)
+}
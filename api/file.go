@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	mc "github.com/frebib/mcmod/minecraft"
 	"github.com/frebib/mcmod/util"
 )
 
@@ -15,6 +16,7 @@ type Files []File
 
 type File struct {
 	ID                      int          `json:"id"`
+	ModID                   int          `json:"modId"`
 	DisplayName             string       `json:"displayName"`
 	FileName                string       `json:"fileName"`
 	FileDate                time.Time    `json:"fileDate"`
@@ -34,13 +36,56 @@ type File struct {
 	HasInstallScript        bool         `json:"hasInstallScript"`
 	GameVersionDateReleased time.Time    `json:"gameVersionDateReleased"`
 	GameVersionFlavor       interface{}  `json:"gameVersionFlavor"`
+
+	// Hashes maps hash algorithm names to hex-encoded hashes of the file, for
+	// backends that provide them
+	Hashes map[string]string `json:"hashes,omitempty"`
 }
 
 type Dependency struct {
-	ID      int `json:"id"`
-	AddonID int `json:"addonId"`
-	Type    int `json:"type"`
-	FileID  int `json:"fileId"`
+	ID      int            `json:"id"`
+	AddonID int            `json:"addonId"`
+	Type    DependencyType `json:"type"`
+	FileID  int            `json:"fileId"`
+}
+
+// DependencyType is the relationship between a file and a mod it depends on,
+// numbered as in the CurseForge API
+type DependencyType int
+
+const (
+	DependencyUnknown DependencyType = iota
+	// DependencyEmbedded mods are shipped inside the file itself
+	DependencyEmbedded
+	DependencyOptional
+	DependencyRequired
+	// DependencyTool mods are used to build or work with the mod
+	DependencyTool
+	// DependencyIncompatible mods must not be installed alongside the file
+	DependencyIncompatible
+	// DependencyInclude mods are bundled alongside the file
+	DependencyInclude
+)
+
+var dependencyTypeNames = [...]string{
+	"unknown", "embedded", "optional", "required", "tool", "incompatible", "include",
+}
+
+func (d DependencyType) String() string {
+	if d < 0 || int(d) >= len(dependencyTypeNames) {
+		return dependencyTypeNames[DependencyUnknown]
+	}
+	return dependencyTypeNames[d]
+}
+
+func ParseDependencyType(s string) DependencyType {
+	s = strings.ToLower(s)
+	for idx, name := range dependencyTypeNames {
+		if name == s {
+			return DependencyType(idx)
+		}
+	}
+	return DependencyUnknown
 }
 
 type Module struct {
@@ -96,12 +141,22 @@ func (c *ApiClient) Files(ctx context.Context, mod int) (files Files, err error)
 		return nil, err
 	}
 
-	resp, err := fetchJSON(ctx, c.HttpClient, "GET", queryUrl, nil)
+	resp, err := fetchJSON(ctx, c.HttpClient, "GET", queryUrl, nil, nil)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
-	return files, json.NewDecoder(resp.Body).Decode(&files)
+	err = json.NewDecoder(resp.Body).Decode(&files)
+	for idx := range files {
+		files[idx].ModID = mod
+	}
+	return files, err
+}
+
+// Dependencies returns the dependencies listed on the file itself, as the
+// CurseForge API already includes them in the file metadata
+func (c *ApiClient) Dependencies(ctx context.Context, file *File) ([]Dependency, error) {
+	return file.Dependencies, nil
 }
 
 type FileFilter struct {
@@ -109,11 +164,43 @@ type FileFilter struct {
 	AfterFunc  func(Files) error
 }
 
+// FileFilterVersion matches files for a game version, or any version that
+// matches a constraint such as "1.16.x". Anything that isn't a valid
+// constraint is matched literally
 func FileFilterVersion(ver string) FileFilter {
 	var verClos = ver
+	constraint, err := mc.ParseConstraint(ver)
+	return FileFilter{
+		func(file *File) bool {
+			if util.StringInSlice(file.GameVersion, verClos) {
+				return true
+			}
+			if err != nil {
+				return false
+			}
+			for _, gameVer := range file.GameVersion {
+				if constraint.CheckString(gameVer) {
+					return true
+				}
+			}
+			return false
+		},
+		nil,
+	}
+}
+
+// FileFilterLoader matches files for the named mod loader, such as forge or
+// fabric. Mod loaders are listed alongside the game versions of a file
+func FileFilterLoader(loader string) FileFilter {
+	var loaderClos = strings.ToLower(loader)
 	return FileFilter{
 		func(file *File) bool {
-			return util.StringInSlice(file.GameVersion, verClos)
+			for _, ver := range file.GameVersion {
+				if strings.ToLower(ver) == loaderClos {
+					return true
+				}
+			}
+			return false
 		},
 		nil,
 	}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDependencyType(t *testing.T) {
+	for dep := DependencyUnknown; dep <= DependencyInclude; dep++ {
+		if parsed := ParseDependencyType(dep.String()); parsed != dep {
+			t.Errorf("dependency type did not round-trip: %s != %s", dep, parsed)
+		}
+	}
+	if parsed := ParseDependencyType("Required"); parsed != DependencyRequired {
+		t.Errorf("expected required, got %s", parsed)
+	}
+	if str := DependencyType(42).String(); str != "unknown" {
+		t.Errorf("expected unknown, got %s", str)
+	}
+}
+
+func TestFileFilterVersion(t *testing.T) {
+	files := Files{
+		{ID: 1, GameVersion: []string{"1.16.4", "Forge"}},
+		{ID: 2, GameVersion: []string{"1.16.5", "Forge"}},
+		{ID: 3, GameVersion: []string{"1.17.1", "Fabric"}},
+		{ID: 4, GameVersion: []string{"1.17-Snapshot"}},
+	}
+	var cases = []struct {
+		version  string
+		expected []int
+	}{
+		{version: "1.16.5", expected: []int{2}},
+		{version: "1.16", expected: []int{1, 2}},
+		{version: ">=1.16.5 <1.18", expected: []int{2, 3}},
+		{version: "1.16.4 || 1.17.x", expected: []int{1, 3}},
+		{version: "1.17-Snapshot", expected: []int{4}},
+		// Invalid constraints are matched literally
+		{version: "Forge", expected: []int{1, 2}},
+	}
+	for _, c := range cases {
+		filtered, err := files.Filter([]FileFilter{FileFilterVersion(c.version)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := make([]int, 0, len(filtered))
+		for _, file := range filtered {
+			ids = append(ids, file.ID)
+		}
+		if !reflect.DeepEqual(ids, c.expected) {
+			t.Errorf("%s: expected files %v, got %v", c.version, c.expected, ids)
+		}
+	}
+}
@@ -2,13 +2,26 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"strconv"
+	"strings"
 
 	modlog "github.com/frebib/mcmod/log"
 )
 
-func (c *ApiClient) Lookup(ctx context.Context, modNameId string) (addon *Addon, err error) {
+// lookupMaxResults is the most search results that are
+// searched through to find a mod with a matching slug
+const lookupMaxResults = 500
+
+func (c *ApiClient) Lookup(ctx context.Context, modNameId string) (*Addon, error) {
+	return lookup(ctx, c, modNameId)
+}
+
+// lookup finds a mod by id, or by searching for a mod with a matching slug or
+// name, using only the search and id methods of a Backend
+func lookup(ctx context.Context, c Backend, modNameId string) (addon *Addon, err error) {
 	log := modlog.FromContext(ctx)
 
 	// If input is not an int, assume it's a mod name
@@ -16,13 +29,23 @@ func (c *ApiClient) Lookup(ctx context.Context, modNameId string) (addon *Addon,
 		log = log.WithField("name", modNameId)
 		log.Debug("searching for mod by name")
 
-		results, err := c.AddonSearch(ctx, AddonSearchOption{
+		// Only fetch as many pages as needed to find the mod by slug
+		it := NewSearchIterator(c, AddonSearchOption{
 			GameId: GameMinecraft,
 			Filter: modNameId,
 		})
-		if err != nil {
+		results := make(SearchResult, 0)
+		for len(results) < lookupMaxResults && it.Next(ctx) {
+			results = append(results, *it.Addon())
+			if strings.EqualFold(it.Addon().Slug, modNameId) {
+				log.Debug("matched mod by slug")
+				return &results[len(results)-1], nil
+			}
+		}
+		if err := it.Err(); err != nil {
 			log.WithError(err).
 				Errorf("failed to search")
+			return nil, err
 		}
 
 		// Found nothing for the search term
@@ -30,14 +53,9 @@ func (c *ApiClient) Lookup(ctx context.Context, modNameId string) (addon *Addon,
 			return nil, &ErrNoSuchAddon{Name: modNameId}
 		}
 
-		addon = results.FindBySlug(modNameId)
+		addon = results.FindByName(modNameId)
 		if addon != nil {
-			log.Debug("matched mod by slug")
-		} else {
-			addon = results.FindByName(modNameId)
-			if addon != nil {
-				log.Debug("matched mod by fileName")
-			}
+			log.Debug("matched mod by fileName")
 		}
 		if addon == nil {
 			// Give up and just return the first result
@@ -48,9 +66,13 @@ func (c *ApiClient) Lookup(ctx context.Context, modNameId string) (addon *Addon,
 			Debug("fetching mod metadata by id")
 
 		addon, err = c.AddonByID(ctx, id)
-		if err != nil {
+		var errStatus *ErrHttpStatus
+		if errors.As(err, &errStatus) && errStatus.Code == http.StatusNotFound {
+			return nil, &ErrNoSuchAddon{ID: id}
+		} else if err != nil {
 			log.WithError(err).
 				Errorf("failed to query")
+			return nil, err
 		}
 
 		// Sanity-check that we have the right mod
@@ -59,7 +81,7 @@ func (c *ApiClient) Lookup(ctx context.Context, modNameId string) (addon *Addon,
 		} else if addon.ID != id {
 			format := "mismatched local id %d to curseforge id %d. aborting"
 			log.Warn(fmt.Sprintf(format, id, addon.ID))
-			return nil, nil
+			return nil, &ErrNoSuchAddon{ID: id}
 		}
 	}
 
@@ -0,0 +1,461 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	modlog "github.com/frebib/mcmod/log"
+	mc "github.com/frebib/mcmod/minecraft"
+)
+
+const ModrinthApiUrl = "https://api.modrinth.com/v2"
+
+// modrinthMaxPageSize is the largest number of search results that Modrinth
+// will return in a single request
+const modrinthMaxPageSize = 100
+
+// ModrinthClient is a Backend for the Modrinth v2 API. Modrinth identifies
+// projects and versions with base62 encoded integers, which are decoded into
+// the integer ids of Addon and File so they can be used interchangeably with
+// those from CurseForge
+type ModrinthClient struct {
+	HttpClient *http.Client
+	ApiUrl     string
+}
+
+var DefaultModrinthClient = &ModrinthClient{
+	HttpClient: new(http.Client),
+	ApiUrl:     ModrinthApiUrl,
+}
+
+var _ Backend = &ModrinthClient{}
+
+func (c *ModrinthClient) Name() string {
+	return "modrinth"
+}
+
+type modrinthSearchResult struct {
+	Hits      []modrinthSearchHit `json:"hits"`
+	Offset    int                 `json:"offset"`
+	Limit     int                 `json:"limit"`
+	TotalHits int                 `json:"total_hits"`
+}
+
+type modrinthSearchHit struct {
+	ProjectID    string    `json:"project_id"`
+	ProjectType  string    `json:"project_type"`
+	Slug         string    `json:"slug"`
+	Author       string    `json:"author"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description"`
+	Categories   []string  `json:"categories"`
+	Versions     []string  `json:"versions"`
+	Downloads    float64   `json:"downloads"`
+	IconURL      string    `json:"icon_url"`
+	DateCreated  time.Time `json:"date_created"`
+	DateModified time.Time `json:"date_modified"`
+}
+
+type modrinthProject struct {
+	ID           string    `json:"id"`
+	Slug         string    `json:"slug"`
+	ProjectType  string    `json:"project_type"`
+	Team         string    `json:"team"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description"`
+	Categories   []string  `json:"categories"`
+	GameVersions []string  `json:"game_versions"`
+	Loaders      []string  `json:"loaders"`
+	Versions     []string  `json:"versions"`
+	Downloads    float64   `json:"downloads"`
+	IconURL      string    `json:"icon_url"`
+	Published    time.Time `json:"published"`
+	Updated      time.Time `json:"updated"`
+}
+
+type modrinthVersion struct {
+	ID            string               `json:"id"`
+	ProjectID     string               `json:"project_id"`
+	Name          string               `json:"name"`
+	VersionNumber string               `json:"version_number"`
+	VersionType   string               `json:"version_type"`
+	GameVersions  []string             `json:"game_versions"`
+	Loaders       []string             `json:"loaders"`
+	DatePublished time.Time            `json:"date_published"`
+	Files         []modrinthFile       `json:"files"`
+	Dependencies  []modrinthDependency `json:"dependencies"`
+}
+
+type modrinthFile struct {
+	Hashes   map[string]string `json:"hashes"`
+	URL      string            `json:"url"`
+	Filename string            `json:"filename"`
+	Primary  bool              `json:"primary"`
+	Size     int               `json:"size"`
+}
+
+type modrinthDependency struct {
+	VersionID      string `json:"version_id"`
+	ProjectID      string `json:"project_id"`
+	FileName       string `json:"file_name"`
+	DependencyType string `json:"dependency_type"`
+}
+
+// ModrinthVersionOption restricts the versions listed for a project
+type ModrinthVersionOption struct {
+	GameVersions []string
+	Loaders      []string
+}
+
+const modrinthIDAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// ModrinthDecodeID converts a base62 Modrinth id into an integer id
+func ModrinthDecodeID(s string) (int, error) {
+	if s == "" {
+		return 0, errors.New("empty modrinth id")
+	}
+	var id uint64
+	for _, r := range s {
+		digit := strings.IndexRune(modrinthIDAlphabet, r)
+		if digit < 0 {
+			return 0, fmt.Errorf("invalid modrinth id '%s'", s)
+		}
+		id = id*62 + uint64(digit)
+	}
+	return int(id), nil
+}
+
+// ModrinthEncodeID converts an integer id into a base62 Modrinth id
+func ModrinthEncodeID(id int) string {
+	if id == 0 {
+		return "0"
+	}
+	var buf []byte
+	for n := uint64(id); n > 0; n /= 62 {
+		buf = append([]byte{modrinthIDAlphabet[n%62]}, buf...)
+	}
+	return string(buf)
+}
+
+func modrinthSortIndex(sort AddonSortMethod) string {
+	switch sort {
+	case AddonSortPopularity, AddonSortTotalDownloads:
+		return "downloads"
+	case AddonSortLastUpdated:
+		return "updated"
+	default:
+		return "relevance"
+	}
+}
+
+func (c *ModrinthClient) fetch(ctx context.Context, urlPath string, params url.Values, v interface{}) error {
+	queryUrl, err := buildURLParams(c.ApiUrl, urlPath, &params)
+	if err != nil {
+		return err
+	}
+
+	resp, err := fetchJSON(ctx, c.HttpClient, "GET", queryUrl, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (c *ModrinthClient) AddonSearch(ctx context.Context, opts AddonSearchOption) (SearchResult, error) {
+	facets := [][]string{{"project_type:mod"}}
+	if opts.GameVersion != "" {
+		facets = append(facets, []string{"versions:" + opts.GameVersion})
+	}
+	facetsJSON, err := json.Marshal(facets)
+	if err != nil {
+		return nil, err
+	}
+
+	setDefaultUnsetOptions(&opts)
+	addons := make(SearchResult, 0)
+	for offset := opts.Index; len(addons) < opts.PageSize; {
+		limit := opts.PageSize - len(addons)
+		if limit > modrinthMaxPageSize {
+			limit = modrinthMaxPageSize
+		}
+		params := url.Values{
+			"query":  {opts.Filter},
+			"facets": {string(facetsJSON)},
+			"index":  {modrinthSortIndex(opts.Sort)},
+			"limit":  {strconv.Itoa(limit)},
+			"offset": {strconv.Itoa(offset)},
+		}
+
+		var result modrinthSearchResult
+		err = c.fetch(ctx, "search", params, &result)
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range result.Hits {
+			addon, err := hit.addon()
+			if err != nil {
+				return nil, err
+			}
+			addons = append(addons, *addon)
+		}
+
+		offset += len(result.Hits)
+		if len(result.Hits) < 1 || offset >= result.TotalHits {
+			break
+		}
+	}
+	return addons, nil
+}
+
+func (c *ModrinthClient) Lookup(ctx context.Context, modNameId string) (*Addon, error) {
+	log := modlog.FromContext(ctx).
+		WithField("name", modNameId)
+
+	// Modrinth resolves both slugs and ids from the same endpoint
+	log.Debug("fetching mod metadata by slug or id")
+	addon, err := c.project(ctx, modNameId)
+	var errStatus *ErrHttpStatus
+	if err == nil {
+		return addon, nil
+	} else if !errors.As(err, &errStatus) || errStatus.Code != http.StatusNotFound {
+		return nil, err
+	}
+
+	log.Debug("searching for mod by name")
+	results, err := c.AddonSearch(ctx, AddonSearchOption{Filter: modNameId})
+	if err != nil {
+		return nil, err
+	}
+	if len(results) < 1 {
+		return nil, &ErrNoSuchAddon{Name: modNameId}
+	}
+	if addon = results.FindByName(modNameId); addon != nil {
+		log.Debug("matched mod by name")
+		return addon, nil
+	}
+	// Give up and just return the first result
+	return &results[0], nil
+}
+
+func (c *ModrinthClient) AddonByID(ctx context.Context, id int) (*Addon, error) {
+	return c.project(ctx, ModrinthEncodeID(id))
+}
+
+func (c *ModrinthClient) project(ctx context.Context, idSlug string) (*Addon, error) {
+	var project modrinthProject
+	err := c.fetch(ctx, "project/"+url.PathEscape(idSlug), nil, &project)
+	if err != nil {
+		return nil, err
+	}
+	return project.addon()
+}
+
+func (c *ModrinthClient) Files(ctx context.Context, mod int) (Files, error) {
+	return c.Versions(ctx, ModrinthEncodeID(mod), ModrinthVersionOption{})
+}
+
+// Versions lists the versions of a project as Files, optionally limited to
+// those for specific game versions and mod loaders
+func (c *ModrinthClient) Versions(ctx context.Context, idSlug string, opts ModrinthVersionOption) (Files, error) {
+	params := url.Values{}
+	if len(opts.GameVersions) > 0 {
+		gameVersions, err := json.Marshal(opts.GameVersions)
+		if err != nil {
+			return nil, err
+		}
+		params.Set("game_versions", string(gameVersions))
+	}
+	if len(opts.Loaders) > 0 {
+		loaders, err := json.Marshal(opts.Loaders)
+		if err != nil {
+			return nil, err
+		}
+		params.Set("loaders", string(loaders))
+	}
+
+	var versions []modrinthVersion
+	path := fmt.Sprintf("project/%s/version", url.PathEscape(idSlug))
+	err := c.fetch(ctx, path, params, &versions)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(Files, 0, len(versions))
+	for _, version := range versions {
+		file, err := version.file()
+		if err != nil {
+			return nil, err
+		}
+		// Versions without any downloadable files are of no use
+		if file != nil {
+			files = append(files, *file)
+		}
+	}
+	return files, nil
+}
+
+// Dependencies returns the dependencies of a file. Modrinth permits depending
+// on a specific version without naming the project, so the project of any such
+// dependency is looked up from the version
+func (c *ModrinthClient) Dependencies(ctx context.Context, file *File) ([]Dependency, error) {
+	deps := make([]Dependency, 0, len(file.Dependencies))
+	for _, dep := range file.Dependencies {
+		if dep.AddonID == 0 && dep.FileID != 0 {
+			var version modrinthVersion
+			path := "version/" + ModrinthEncodeID(dep.FileID)
+			err := c.fetch(ctx, path, nil, &version)
+			if err != nil {
+				return nil, err
+			}
+			dep.AddonID, err = ModrinthDecodeID(version.ProjectID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		deps = append(deps, dep)
+	}
+	return deps, nil
+}
+
+func modrinthGameVersions(versions []string) []AddonGameVersionLatestFile {
+	latest := make([]AddonGameVersionLatestFile, 0, len(versions))
+	for _, version := range versions {
+		// Skip versions that can't be represented, such as April Fools releases
+		ver, err := mc.Parse(version)
+		if err != nil {
+			continue
+		}
+		latest = append(latest, AddonGameVersionLatestFile{GameVersion: *ver})
+	}
+	return latest
+}
+
+func modrinthCategories(categories []string) []AddonCategory {
+	cats := make([]AddonCategory, len(categories))
+	for idx, category := range categories {
+		cats[idx] = AddonCategory{Name: category}
+	}
+	return cats
+}
+
+func (h *modrinthSearchHit) addon() (*Addon, error) {
+	id, err := ModrinthDecodeID(h.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	return &Addon{
+		ID:                     id,
+		Name:                   h.Title,
+		Authors:                []AddonAuthor{{Name: h.Author}},
+		WebsiteURL:             "https://modrinth.com/mod/" + h.Slug,
+		Summary:                h.Description,
+		DownloadCount:          h.Downloads,
+		Categories:             modrinthCategories(h.Categories),
+		Slug:                   h.Slug,
+		GameVersionLatestFiles: modrinthGameVersions(h.Versions),
+		DateModified:           h.DateModified,
+		DateCreated:            h.DateCreated,
+		IsAvailable:            true,
+	}, nil
+}
+
+func (p *modrinthProject) addon() (*Addon, error) {
+	id, err := ModrinthDecodeID(p.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &Addon{
+		ID:                     id,
+		Name:                   p.Title,
+		WebsiteURL:             "https://modrinth.com/mod/" + p.Slug,
+		Summary:                p.Description,
+		DownloadCount:          p.Downloads,
+		Categories:             modrinthCategories(p.Categories),
+		Slug:                   p.Slug,
+		GameVersionLatestFiles: modrinthGameVersions(p.GameVersions),
+		DateModified:           p.Updated,
+		DateCreated:            p.Published,
+		DateReleased:           p.Published,
+		IsAvailable:            true,
+	}, nil
+}
+
+// file converts a Modrinth version into a File, using the primary file of the
+// version as the download. nil is returned if the version has no files
+func (v *modrinthVersion) file() (*File, error) {
+	if len(v.Files) < 1 {
+		return nil, nil
+	}
+	primary := v.Files[0]
+	for _, f := range v.Files {
+		if f.Primary {
+			primary = f
+			break
+		}
+	}
+
+	id, err := ModrinthDecodeID(v.ID)
+	if err != nil {
+		return nil, err
+	}
+	var modID int
+	if v.ProjectID != "" {
+		modID, err = ModrinthDecodeID(v.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	deps := make([]Dependency, 0, len(v.Dependencies))
+	for _, dep := range v.Dependencies {
+		var d Dependency
+		if dep.ProjectID != "" {
+			d.AddonID, err = ModrinthDecodeID(dep.ProjectID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if dep.VersionID != "" {
+			d.FileID, err = ModrinthDecodeID(dep.VersionID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		// Dependencies referencing a bare file name can't be resolved
+		if d.AddonID == 0 && d.FileID == 0 {
+			continue
+		}
+		d.Type = ParseDependencyType(dep.DependencyType)
+		deps = append(deps, d)
+	}
+
+	// CurseForge lists mod loaders alongside the game versions, so do the same
+	// here so that the same filters apply to files from either backend
+	gameVersions := append(make([]string, 0), v.GameVersions...)
+	for _, loader := range v.Loaders {
+		gameVersions = append(gameVersions, strings.Title(loader))
+	}
+
+	return &File{
+		ID:           id,
+		ModID:        modID,
+		DisplayName:  v.Name,
+		FileName:     primary.Filename,
+		FileDate:     v.DatePublished,
+		FileLength:   primary.Size,
+		ReleaseType:  ParseReleaseType(v.VersionType),
+		DownloadURL:  primary.URL,
+		Dependencies: deps,
+		IsAvailable:  true,
+		GameVersion:  gameVersions,
+		Hashes:       primary.Hashes,
+	}, nil
+}
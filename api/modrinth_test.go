@@ -0,0 +1,153 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+var modrinthResponses = map[string]string{
+	"/v2/search": `{"hits": [{
+		"project_id": "AANobbMI", "slug": "sodium", "title": "Sodium",
+		"author": "jellysquid3", "downloads": 1000,
+		"versions": ["1.16.5", "1.17.1", "21w03a"]
+	}], "offset": 0, "limit": 10, "total_hits": 1}`,
+	"/v2/project/sodium": `{
+		"id": "AANobbMI", "slug": "sodium", "title": "Sodium",
+		"game_versions": ["1.16.5"], "loaders": ["fabric"]
+	}`,
+	"/v2/project/AANobbMI/version": `[{
+		"id": "yaoBL9D9", "project_id": "AANobbMI", "name": "Sodium 0.2.0",
+		"version_type": "beta", "game_versions": ["1.16.5"], "loaders": ["fabric"],
+		"date_published": "2021-01-03T00:00:00Z",
+		"files": [
+			{"url": "https://cdn/sources.jar", "filename": "sources.jar", "size": 1},
+			{"url": "https://cdn/sodium.jar", "filename": "sodium.jar", "size": 2,
+			 "primary": true, "hashes": {"sha1": "abc"}}
+		],
+		"dependencies": [
+			{"project_id": "P7dR8mSH", "dependency_type": "required"},
+			{"version_id": "tFw0iWAk", "dependency_type": "optional"}
+		]
+	}]`,
+	"/v2/version/tFw0iWAk": `{"id": "tFw0iWAk", "project_id": "mOgUt4GM"}`,
+}
+
+func newModrinthTestClient(t *testing.T) *ModrinthClient {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := modrinthResponses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return &ModrinthClient{HttpClient: srv.Client(), ApiUrl: srv.URL + "/v2"}
+}
+
+func TestModrinthIDs(t *testing.T) {
+	for _, id := range []string{"AANobbMI", "P7dR8mSH", "yaoBL9D9", "1"} {
+		num, err := ModrinthDecodeID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if enc := ModrinthEncodeID(num); enc != id {
+			t.Errorf("id did not round-trip: %s != %s", id, enc)
+		}
+	}
+	if _, err := ModrinthDecodeID("not-an-id"); err == nil {
+		t.Error("expected error decoding invalid id")
+	}
+}
+
+func TestModrinthSearch(t *testing.T) {
+	client := newModrinthTestClient(t)
+	results, err := client.AddonSearch(context.Background(), AddonSearchOption{Filter: "sodium"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addon := results.FindBySlug("sodium")
+	if addon == nil {
+		t.Fatalf("expected result with slug sodium, got %#v", results)
+	}
+	if addon.ID != modrinthTestID(t, "AANobbMI") || addon.Authors[0].Name != "jellysquid3" {
+		t.Errorf("unexpected addon: %#v", addon)
+	}
+	expected := []string{"1.16.5", "1.17.1", "21w03a"}
+	if versions := addon.SupportedVersions().Strings(); !reflect.DeepEqual(versions, expected) {
+		t.Errorf("unexpected supported versions: %v", versions)
+	}
+}
+
+func TestModrinthLookup(t *testing.T) {
+	client := newModrinthTestClient(t)
+	addon, err := client.Lookup(context.Background(), "sodium")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addon.ID != modrinthTestID(t, "AANobbMI") || addon.Name != "Sodium" {
+		t.Errorf("unexpected addon: %#v", addon)
+	}
+
+	// Unknown slugs fall back to searching
+	addon, err = client.Lookup(context.Background(), "Sodium")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addon.Slug != "sodium" {
+		t.Errorf("unexpected addon: %#v", addon)
+	}
+}
+
+func TestModrinthFiles(t *testing.T) {
+	client := newModrinthTestClient(t)
+	ctx := context.Background()
+	files, err := client.Files(ctx, modrinthTestID(t, "AANobbMI"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected 1 file, got %d", len(files))
+	}
+	file := files[0]
+	if file.ModID != modrinthTestID(t, "AANobbMI") ||
+		file.FileName != "sodium.jar" || file.FileLength != 2 ||
+		file.ReleaseType != ReleaseBeta || file.Hashes["sha1"] != "abc" {
+		t.Errorf("unexpected file: %#v", file)
+	}
+	if !reflect.DeepEqual(file.GameVersion, []string{"1.16.5", "Fabric"}) {
+		t.Errorf("unexpected game versions: %v", file.GameVersion)
+	}
+
+	filtered, err := files.Filter([]FileFilter{FileFilterLoader("fabric")})
+	if err != nil || len(filtered) != 1 {
+		t.Errorf("expected loader filter to match file: %v", err)
+	}
+
+	deps, err := client.Dependencies(ctx, &file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Dependency{
+		{AddonID: modrinthTestID(t, "P7dR8mSH"), Type: DependencyRequired},
+		{
+			AddonID: modrinthTestID(t, "mOgUt4GM"),
+			FileID:  modrinthTestID(t, "tFw0iWAk"),
+			Type:    DependencyOptional,
+		},
+	}
+	if !reflect.DeepEqual(deps, expected) {
+		t.Errorf("unexpected dependencies:\nexpected: %#v\ngot:      %#v", expected, deps)
+	}
+}
+
+func modrinthTestID(t *testing.T, id string) int {
+	num, err := ModrinthDecodeID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return num
+}
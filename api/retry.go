@@ -0,0 +1,168 @@
+package api
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	modlog "github.com/frebib/mcmod/log"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// DefaultRetries is the number of times a failed request is retried
+	DefaultRetries = 4
+	// DefaultRateLimit is the number of requests permitted per second
+	DefaultRateLimit = 10
+	// DefaultRateBurst is the number of requests permitted at once
+	DefaultRateBurst = 10
+
+	defaultMinBackoff = 500 * time.Millisecond
+	defaultMaxBackoff = 30 * time.Second
+)
+
+// RetryPolicy retries failed idempotent requests with exponential backoff and
+// jitter, and limits the rate of all requests made through it. A single
+// RetryPolicy should be shared by every concurrent user of an API so that they
+// are limited together
+type RetryPolicy struct {
+	// Retries is the maximum number of times a request is retried
+	Retries int
+	// MinBackoff is the longest wait before the first retry, which doubles
+	// with each following retry up to MaxBackoff
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Limiter limits the rate of requests, including retries. A nil Limiter
+	// permits requests at any rate
+	Limiter *rate.Limiter
+}
+
+// NewRetryPolicy returns a RetryPolicy that retries failed requests up to
+// retries times, and permits limit requests per second, with bursts of burst
+// requests at a time. A limit of zero or less disables rate limiting. A burst
+// of less than one still permits one request at a time
+func NewRetryPolicy(retries int, limit float64, burst int) *RetryPolicy {
+	policy := &RetryPolicy{
+		Retries:    retries,
+		MinBackoff: defaultMinBackoff,
+		MaxBackoff: defaultMaxBackoff,
+	}
+	if limit > 0 {
+		// A limiter with no burst never permits any request
+		if burst < 1 {
+			burst = 1
+		}
+		policy.Limiter = rate.NewLimiter(rate.Limit(limit), burst)
+	}
+	return policy
+}
+
+// Transport wraps next, an http.RoundTripper, with the retry policy. If next
+// is nil then http.DefaultTransport is used
+func (p *RetryPolicy) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &retryTransport{policy: p, next: next}
+}
+
+// backoff returns how long to wait before the given retry attempt, using the
+// Retry-After header of the failed response, if there is one
+func (p *RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if after := parseRetryAfter(resp.Header.Get("Retry-After")); after > 0 {
+			return after
+		}
+	}
+	backoff := p.MinBackoff << uint(attempt)
+	if backoff > p.MaxBackoff || backoff <= 0 {
+		backoff = p.MaxBackoff
+	}
+	// Full jitter spreads out the retries of concurrent requests
+	return time.Duration(rand.Int63n(int64(backoff) + 1))
+}
+
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+	return 0
+}
+
+// isRetryable reports whether a request should be retried after
+// failing with the given response status code
+func isRetryable(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+type retryTransport struct {
+	policy *RetryPolicy
+	next   http.RoundTripper
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	log := modlog.FromContext(ctx).
+		WithField("url", req.URL.String())
+
+	// Only idempotent requests are safe to repeat
+	retries := t.policy.Retries
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		retries = 0
+	}
+
+	for attempt := 0; ; attempt++ {
+		if t.policy.Limiter != nil {
+			reservation := t.policy.Limiter.Reserve()
+			if delay := reservation.Delay(); delay > 0 {
+				log.Tracef("rate limited, waiting %s", delay.Round(time.Millisecond))
+				select {
+				case <-time.After(delay):
+				case <-ctx.Done():
+					reservation.Cancel()
+					return nil, ctx.Err()
+				}
+			}
+		}
+
+		resp, err := t.next.RoundTrip(req)
+		if ctx.Err() != nil || attempt >= retries {
+			return resp, err
+		}
+		if err == nil && !isRetryable(resp.StatusCode) {
+			return resp, nil
+		}
+
+		wait := t.policy.backoff(attempt, resp)
+		reqLog := log.WithField("attempt", attempt+1)
+		if err != nil {
+			reqLog = reqLog.WithError(err)
+		} else {
+			reqLog = reqLog.WithField("status", resp.StatusCode)
+			_ = resp.Body.Close()
+		}
+		reqLog.Tracef("request failed, retrying in %s (%d retries left)",
+			wait.Round(time.Millisecond), retries-attempt)
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		// Fail the first two requests of each method
+		if requests%3 != 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	policy := NewRetryPolicy(2, 0, 0)
+	policy.MinBackoff = time.Millisecond
+	client := &http.Client{Transport: policy.Transport(srv.Client().Transport)}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || requests != 3 {
+		t.Errorf("expected success after 3 requests, got %d after %d", resp.StatusCode, requests)
+	}
+
+	// Requests that aren't idempotent are never retried
+	requests = 0
+	resp, err = client.Post(srv.URL, "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || requests != 1 {
+		t.Errorf("expected failure after 1 request, got %d after %d", resp.StatusCode, requests)
+	}
+}
+
+func TestRetryPolicyBurst(t *testing.T) {
+	policy := NewRetryPolicy(0, 1, 0)
+	if policy.Limiter.Burst() != 1 || !policy.Limiter.Allow() {
+		t.Errorf("expected a burst of 1, got %d", policy.Limiter.Burst())
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if after := parseRetryAfter("3"); after != 3*time.Second {
+		t.Errorf("expected 3s, got %s", after)
+	}
+	date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if after := parseRetryAfter(date); after <= 0 || after > time.Minute {
+		t.Errorf("expected up to 1m, got %s", after)
+	}
+	if after := parseRetryAfter("soon"); after != 0 {
+		t.Errorf("expected 0 for invalid header, got %s", after)
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+)
+
+// SearchIterator lazily fetches search results from a Backend one page at a
+// time, starting from the Index of the search options and fetching PageSize
+// results in each request. Results are only fetched as they are needed, so
+// stopping early avoids fetching the remaining pages
+type SearchIterator struct {
+	backend Backend
+	opts    AddonSearchOption
+	page    SearchResult
+	idx     int
+	done    bool
+	err     error
+}
+
+// NewSearchIterator returns an iterator over the results of a search
+func NewSearchIterator(backend Backend, opts AddonSearchOption) *SearchIterator {
+	return &SearchIterator{
+		backend: backend,
+		opts:    *setDefaultUnsetOptions(&opts),
+		idx:     -1,
+	}
+}
+
+// Next advances the iterator to the next result, fetching the next page of
+// results if needed. It returns false once the results are exhausted, or if
+// fetching a page fails, in which case Err returns the error
+func (it *SearchIterator) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	it.idx++
+	if it.idx < len(it.page) {
+		return true
+	}
+	if it.done {
+		return false
+	}
+
+	page, err := it.backend.AddonSearch(ctx, it.opts)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	// Backends may return fewer results than asked for, such as when the page
+	// size is larger than they allow, so only an empty page is the last
+	if len(page) < 1 {
+		it.done = true
+	}
+	it.opts.Index += len(page)
+	it.page = page
+	it.idx = 0
+	return len(page) > 0
+}
+
+// Addon returns the current result
+func (it *SearchIterator) Addon() *Addon {
+	return &it.page[it.idx]
+}
+
+// Err returns the error that stopped the iterator, if any
+func (it *SearchIterator) Err() error {
+	return it.err
+}
+
+// Collect gathers up to max results from the iterator, or
+// all remaining results if max is zero or less
+func (it *SearchIterator) Collect(ctx context.Context, max int) (SearchResult, error) {
+	results := make(SearchResult, 0)
+	for (max <= 0 || len(results) < max) && it.Next(ctx) {
+		results = append(results, *it.Addon())
+	}
+	return results, it.Err()
+}
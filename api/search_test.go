@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// fakeBackend serves search results from a fixed list of addons
+type fakeBackend struct {
+	Backend
+	addons   SearchResult
+	searches int
+	// maxPage is the most results returned at once, if set
+	maxPage int
+}
+
+func (b *fakeBackend) AddonSearch(ctx context.Context, opts AddonSearchOption) (SearchResult, error) {
+	b.searches++
+	setDefaultUnsetOptions(&opts)
+	if opts.Index >= len(b.addons) {
+		return SearchResult{}, nil
+	}
+	pageSize := opts.PageSize
+	if b.maxPage > 0 && pageSize > b.maxPage {
+		pageSize = b.maxPage
+	}
+	end := opts.Index + pageSize
+	if end > len(b.addons) {
+		end = len(b.addons)
+	}
+	return b.addons[opts.Index:end], nil
+}
+
+func (b *fakeBackend) AddonByID(ctx context.Context, id int) (*Addon, error) {
+	for idx := range b.addons {
+		if b.addons[idx].ID == id {
+			return &b.addons[idx], nil
+		}
+	}
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/addon/%d", id), nil)
+	return nil, &ErrHttpStatus{Req: req, Code: http.StatusNotFound}
+}
+
+// mismatchBackend returns its first addon for any id
+type mismatchBackend struct {
+	*fakeBackend
+}
+
+func (b *mismatchBackend) AddonByID(ctx context.Context, id int) (*Addon, error) {
+	return &b.addons[0], nil
+}
+
+func newFakeBackend(count int) *fakeBackend {
+	backend := new(fakeBackend)
+	for id := 1; id <= count; id++ {
+		backend.addons = append(backend.addons, Addon{
+			ID:   id,
+			Name: fmt.Sprintf("Mod %d", id),
+			Slug: fmt.Sprintf("mod-%d", id),
+		})
+	}
+	return backend
+}
+
+func TestSearchIterator(t *testing.T) {
+	var cases = []struct {
+		max      int
+		pageSize int
+		maxPage  int
+		results  int
+		searches int
+	}{
+		{max: 5, pageSize: 2, results: 5, searches: 3},
+		{max: 4, pageSize: 2, results: 4, searches: 2},
+		{max: 0, pageSize: 3, results: 7, searches: 4},
+		{max: 0, pageSize: 7, results: 7, searches: 2},
+		{max: 10, pageSize: 0, results: 7, searches: 2},
+		// Short pages don't end the results early
+		{max: 0, pageSize: 5, maxPage: 2, results: 7, searches: 5},
+	}
+
+	for _, c := range cases {
+		backend := newFakeBackend(7)
+		backend.maxPage = c.maxPage
+		it := NewSearchIterator(backend, AddonSearchOption{PageSize: c.pageSize})
+		results, err := it.Collect(context.Background(), c.max)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != c.results || backend.searches != c.searches {
+			t.Errorf("max %d, page size %d: expected %d results in %d searches, got %d in %d",
+				c.max, c.pageSize, c.results, c.searches, len(results), backend.searches)
+		}
+	}
+}
+
+func TestLookupStopsAtSlug(t *testing.T) {
+	backend := newFakeBackend(2 * DefaultPageSize)
+	addon, err := lookup(context.Background(), backend, "mod-3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addon.ID != 3 || backend.searches != 1 {
+		t.Errorf("expected mod 3 after 1 search, got %d after %d", addon.ID, backend.searches)
+	}
+
+	// Unmatched slugs search every page before falling back to the name
+	backend.searches = 0
+	addon, err = lookup(context.Background(), backend, "Mod 4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addon.ID != 4 || backend.searches != 3 {
+		t.Errorf("expected mod 4 after 3 searches, got %d after %d", addon.ID, backend.searches)
+	}
+}
+
+func TestLookupByID(t *testing.T) {
+	backend := newFakeBackend(2)
+	addon, err := lookup(context.Background(), backend, "2")
+	if err != nil || addon.ID != 2 {
+		t.Errorf("expected mod 2, got %v", err)
+	}
+
+	var errNoSuch *ErrNoSuchAddon
+	if _, err := lookup(context.Background(), backend, "3"); !errors.As(err, &errNoSuch) {
+		t.Errorf("expected ErrNoSuchAddon, got %v", err)
+	}
+
+	// The backend returning a different mod is an error too
+	mismatched := &mismatchBackend{newFakeBackend(1)}
+	if addon, err := lookup(context.Background(), mismatched, "4"); addon != nil || !errors.As(err, &errNoSuch) {
+		t.Errorf("expected ErrNoSuchAddon, got %v", err)
+	}
+
+	// Other errors are returned as they are
+	client := newCurseForgeTestClient(t)
+	client.ApiKey = ""
+	if _, err := lookup(context.Background(), client, "3"); err != ErrNoApiKey {
+		t.Errorf("expected ErrNoApiKey, got %v", err)
+	}
+}
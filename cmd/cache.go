@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/frebib/mcmod/api"
+	"github.com/frebib/mcmod/download"
+	modlog "github.com/frebib/mcmod/log"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	Cache = &cli.Command{
+		Name:  "cache",
+		Usage: "manage cached API responses and downloaded files",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "clean",
+				Usage:  "remove all cached API responses",
+				Action: cmdDoCacheClean,
+			},
+			{
+				Name:   "ls",
+				Usage:  "list the files in the download store",
+				Action: cmdDoCacheList,
+			},
+			{
+				Name:   "du",
+				Usage:  "show the disk usage of the download store",
+				Action: cmdDoCacheUsage,
+			},
+			{
+				Name:  "prune",
+				Usage: "remove files from the download store that haven't been used recently",
+				Flags: []cli.Flag{
+					&flagOlderThan,
+					&flagDryRun,
+				},
+				Action: cmdDoCachePrune,
+			},
+		},
+	}
+)
+
+func cmdDoCacheClean(c *cli.Context) error {
+	ctx := c.Context
+	log := modlog.FromContext(ctx)
+
+	cache := api.CacheFromContext(ctx)
+	if cache == nil {
+		return errors.New("no cache configured")
+	}
+	log = log.WithField("dir", cache.Dir)
+
+	removed, err := cache.Clean()
+	if err != nil {
+		log.WithError(err).Error("failed to clean cache")
+		return err
+	}
+	log.Infof("removed %d cached responses", removed)
+	return nil
+}
+
+// downloadStore returns the download store configured in the context
+func downloadStore(ctx context.Context) (*download.Store, error) {
+	store := download.ManagerFromContext(ctx).Store
+	if store == nil {
+		return nil, errors.New("no download store configured")
+	}
+	return store, nil
+}
+
+func cmdDoCacheList(c *cli.Context) error {
+	store, err := downloadStore(c.Context)
+	if err != nil {
+		return err
+	}
+	entries, err := store.Entries()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "Key\tSize\tLast used\tFile")
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			entry.Key,
+			humanize.IBytes(uint64(entry.Size)),
+			humanize.Time(entry.Used),
+			filepath.Base(entry.Path),
+		)
+	}
+	return w.Flush()
+}
+
+func cmdDoCacheUsage(c *cli.Context) error {
+	store, err := downloadStore(c.Context)
+	if err != nil {
+		return err
+	}
+	count, size, err := store.Usage()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\t%d files\t%s\n", humanize.IBytes(uint64(size)), count, store.Dir)
+	return nil
+}
+
+func cmdDoCachePrune(c *cli.Context) error {
+	ctx := c.Context
+	log := modlog.FromContext(ctx)
+
+	store, err := downloadStore(ctx)
+	if err != nil {
+		return err
+	}
+	log = log.WithField("dir", store.Dir)
+	before := time.Now().Add(-c.Duration(flagOlderThan.Name))
+
+	var removed []*download.StoreEntry
+	if c.Bool(flagDryRun.Name) {
+		entries, err := store.Entries()
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if entry.Used.Before(before) {
+				removed = append(removed, entry)
+			}
+		}
+	} else {
+		removed, err = store.Prune(before)
+		if err != nil {
+			log.WithError(err).Error("failed to prune download store")
+			return err
+		}
+	}
+
+	var size int64
+	for _, entry := range removed {
+		log.WithField("key", entry.Key).Debug("pruned stored file")
+		size += entry.Size
+	}
+	log.Infof("pruned %d stored files, freeing %s", len(removed), humanize.IBytes(uint64(size)))
+	return nil
+}
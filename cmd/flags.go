@@ -1,7 +1,10 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/frebib/mcmod/api"
+	"github.com/frebib/mcmod/manifest"
 	"github.com/urfave/cli/v2"
 )
 
@@ -19,12 +22,32 @@ var (
 		Aliases: []string{"o"},
 		EnvVars: []string{"OUTPUT_FILENAME"},
 	}
+	flagManifest = cli.PathFlag{
+		Name:    "manifest",
+		Usage:   "path to the mod list manifest",
+		Aliases: []string{"m"},
+		Value:   manifest.FileName,
+		EnvVars: []string{"MOD_MANIFEST"},
+	}
+	flagSide = cli.StringFlag{
+		Name:    "side",
+		Usage:   "only install mods needed on this side, of [both, client, server]",
+		Aliases: []string{"s"},
+		Value:   string(manifest.SideBoth),
+		EnvVars: []string{"MOD_SIDE"},
+	}
 	flagVersion = cli.StringFlag{
 		Name:    "gamever",
-		Usage:   "game version",
+		Usage:   "game version or constraint, such as 1.16.x, '>=1.16.2 <1.17' or latest-release",
 		Aliases: []string{"V"},
 		EnvVars: []string{"MINECRAFT_VERSION"},
 	}
+	flagLoader = cli.StringFlag{
+		Name:    "loader",
+		Usage:   "mod loader, such as forge or fabric",
+		Aliases: []string{"L"},
+		EnvVars: []string{"MOD_LOADER"},
+	}
 	flagRelease = cli.StringFlag{
 		Name:    "release",
 		Usage:   "release type, of [any, release, beta, alpha]",
@@ -38,4 +61,41 @@ var (
 		Aliases: []string{"D"},
 		EnvVars: []string{"SKIP_DEPENDENCIES"},
 	}
+	flagWithOptional = cli.BoolFlag{
+		Name:    "with-optional",
+		Usage:   "also download optional dependencies",
+		Aliases: []string{"O"},
+		EnvVars: []string{"WITH_OPTIONAL"},
+	}
+	flagReResolve = cli.BoolFlag{
+		Name:    "re-resolve",
+		Usage:   "ignore the lockfile and resolve the newest files again",
+		Aliases: []string{"R"},
+	}
+	flagDryRun = cli.BoolFlag{
+		Name:    "dry-run",
+		Usage:   "show what would be changed, without changing anything",
+		Aliases: []string{"n"},
+	}
+	flagKeepDeps = cli.BoolFlag{
+		Name:    "keep-deps",
+		Usage:   "don't remove dependencies that are no longer needed",
+		Aliases: []string{"k"},
+	}
+	flagFormat = cli.StringFlag{
+		Name:    "format",
+		Usage:   "output format, of [table, json]",
+		Aliases: []string{"f"},
+		Value:   formatTable,
+	}
+	flagForce = cli.BoolFlag{
+		Name:    "force",
+		Usage:   "download files even if they are already present and valid",
+		EnvVars: []string{"FORCE_DOWNLOAD"},
+	}
+	flagOlderThan = cli.DurationFlag{
+		Name:  "older-than",
+		Usage: "only remove files last used longer ago than this",
+		Value: 30 * 24 * time.Hour,
+	}
 )
@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/frebib/mcmod/api"
+	"github.com/frebib/mcmod/fingerprint"
+	modlog "github.com/frebib/mcmod/log"
+	"github.com/frebib/mcmod/manifest"
+	"github.com/frebib/mcmod/resolver"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	Import = &cli.Command{
+		Name:      "import",
+		Usage:     "identify the mods in a directory, writing a manifest and lockfile",
+		Action:    cmdDoImport,
+		ArgsUsage: "<dir>",
+		Flags: []cli.Flag{
+			&flagManifest,
+			&flagVersion,
+			&flagLoader,
+		},
+	}
+)
+
+// importedJar is a jar in the directory being imported
+type importedJar struct {
+	Path        string
+	Fingerprint int64
+	Match       *api.FingerprintMatch
+	Addon       *api.Addon
+}
+
+func cmdDoImport(c *cli.Context) error {
+	ctx := c.Context
+	log := modlog.FromContext(ctx)
+
+	if c.NArg() < 1 {
+		log.Error("missing required arg: " + c.Command.ArgsUsage)
+		return cli.ShowSubcommandHelp(c)
+	}
+	dir := c.Args().First()
+
+	// Don't overwrite a manifest that may have been written by hand
+	manifestPath := c.Path(flagManifest.Name)
+	if _, err := os.Stat(manifestPath); err == nil {
+		return fmt.Errorf("manifest '%s' already exists", manifestPath)
+	}
+
+	backend := api.BackendFromContext(ctx)
+	matcher, ok := backend.(api.FingerprintMatcher)
+	if !ok {
+		return fmt.Errorf("backend '%s' can't identify files", backend.Name())
+	}
+
+	jars, err := fingerprintJars(dir)
+	if err != nil {
+		return err
+	}
+	if len(jars) < 1 {
+		return fmt.Errorf("no jars found in '%s'", dir)
+	}
+	log.Infof("identifying %d jars in '%s'", len(jars), dir)
+
+	fingerprints := make([]int64, len(jars))
+	for idx, jar := range jars {
+		fingerprints[idx] = jar.Fingerprint
+	}
+	matches, err := matcher.FingerprintMatch(ctx, fingerprints)
+	if err != nil {
+		log.WithError(err).Error("failed to match fingerprints")
+		return err
+	}
+	for idx := range matches.ExactMatches {
+		match := &matches.ExactMatches[idx]
+		for _, jar := range jars {
+			if jar.Fingerprint == match.File.PackageFingerprint {
+				jar.Match = match
+			}
+		}
+	}
+
+	// Look up each matched mod for its slug, concurrently
+	wg := new(sync.WaitGroup)
+	for _, jar := range jars {
+		if jar.Match == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(jar *importedJar) {
+			defer wg.Done()
+			addon, err := backend.AddonByID(ctx, jar.Match.ID)
+			if err != nil {
+				log.WithError(err).WithField("file", filepath.Base(jar.Path)).
+					Warn("failed to lookup mod")
+				return
+			}
+			jar.Addon = addon
+		}(jar)
+	}
+	wg.Wait()
+
+	mf, lock := importManifest(backend.Name(), jars, c.String(flagVersion.Name), c.String(flagLoader.Name))
+	err = mf.Save(manifestPath)
+	if err != nil {
+		log.WithError(err).Errorf("failed to write manifest '%s'", manifestPath)
+		return err
+	}
+	lockPath := manifest.LockPath(manifestPath)
+	err = lock.Save(lockPath)
+	if err != nil {
+		log.WithError(err).Errorf("failed to write lockfile '%s'", lockPath)
+		return err
+	}
+	log.Infof("imported %d mods into '%s'", len(lock.Mods), manifestPath)
+
+	return printImport(jars)
+}
+
+// fingerprintJars calculates the fingerprint of every jar in a directory
+func fingerprintJars(dir string) ([]*importedJar, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var jars []*importedJar
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".jar") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		sum, err := fingerprint.File(path)
+		if err != nil {
+			return nil, err
+		}
+		jars = append(jars, &importedJar{Path: path, Fingerprint: int64(sum)})
+	}
+	return jars, nil
+}
+
+// importManifest builds a manifest and lockfile from the identified jars. Mods
+// that another imported mod depends upon are locked as dependencies, rather
+// than being added to the manifest
+func importManifest(backend string, jars []*importedJar, version, loader string) (*manifest.Manifest, *manifest.Lock) {
+	mf := &manifest.Manifest{GameVersion: version, Loader: loader}
+	lock := &manifest.Lock{
+		Backend:     backend,
+		GameVersion: version,
+		Loader:      loader,
+		Side:        manifest.SideBoth,
+	}
+
+	imported := make(map[int]bool)
+	for _, jar := range jars {
+		if jar.Match != nil {
+			imported[jar.Match.ID] = true
+		}
+	}
+	needed := make(map[int]bool)
+	for _, jar := range jars {
+		if jar.Match == nil {
+			continue
+		}
+		for _, dep := range jar.Match.File.Dependencies {
+			if resolver.FollowRequired(dep) && dep.AddonID != jar.Match.ID {
+				needed[dep.AddonID] = true
+			}
+		}
+	}
+
+	var roots, deps []manifest.LockedMod
+	seen := make(map[int]bool)
+	for _, jar := range jars {
+		if jar.Match == nil || seen[jar.Match.ID] {
+			continue
+		}
+		seen[jar.Match.ID] = true
+		locked := manifest.LockedMod{
+			ID:      jar.Match.ID,
+			Root:    !needed[jar.Match.ID],
+			Release: mf.ReleaseOf(nil),
+		}
+		if jar.Addon != nil {
+			locked.Slug = jar.Addon.Slug
+		}
+		for _, dep := range jar.Match.File.Dependencies {
+			if resolver.FollowOptional(dep) && imported[dep.AddonID] {
+				locked.Dependencies = append(locked.Dependencies, dep.AddonID)
+			}
+		}
+		setLockedFile(&locked, &jar.Match.File)
+		// The downloaded name may differ from the name of the local file
+		locked.FileName = filepath.Base(jar.Path)
+
+		if locked.Root {
+			mod := manifest.Mod{Slug: locked.Slug}
+			if mod.Slug == "" {
+				mod.ID = locked.ID
+			}
+			mf.Mods = append(mf.Mods, mod)
+			roots = append(roots, locked)
+		} else {
+			deps = append(deps, locked)
+		}
+	}
+	// Dependencies are installed before the mods that need them
+	lock.Mods = append(deps, roots...)
+	return mf, lock
+}
+
+// printImport writes a table of the identified jars, followed by a list of
+// any jars that couldn't be identified
+func printImport(jars []*importedJar) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprint(w, "File\tID\tSlug\tFile ID\n")
+	var unmatched []string
+	for _, jar := range jars {
+		if jar.Match == nil {
+			unmatched = append(unmatched, filepath.Base(jar.Path))
+			continue
+		}
+		slug := ""
+		if jar.Addon != nil {
+			slug = jar.Addon.Slug
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%d\n",
+			filepath.Base(jar.Path), jar.Match.ID, slug, jar.Match.File.ID)
+	}
+	err := w.Flush()
+	if err != nil || len(unmatched) < 1 {
+		return err
+	}
+
+	fmt.Printf("\n%d unmatched jars:\n", len(unmatched))
+	for _, name := range unmatched {
+		fmt.Printf("  %s\n", name)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"sync"
+
+	"github.com/frebib/mcmod/api"
+	modlog "github.com/frebib/mcmod/log"
+	"github.com/frebib/mcmod/manifest"
+	"github.com/frebib/mcmod/resolver"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	Install = &cli.Command{
+		Name:   "install",
+		Usage:  "download every mod in a manifest",
+		Action: cmdDoInstall,
+		Flags: []cli.Flag{
+			&flagManifest,
+			&flagDirectory,
+			&flagSide,
+			&flagWithOptional,
+			&flagReResolve,
+			&flagForce,
+		},
+	}
+)
+
+// cmdDoInstall installs the files recorded in the lockfile alongside the
+// manifest. If there is no lockfile, or the manifest has changed since it was
+// written, the newest files are resolved and the lockfile is written again
+func cmdDoInstall(c *cli.Context) error {
+	ctx := c.Context
+	log := modlog.FromContext(ctx)
+
+	manifestPath := c.Path(flagManifest.Name)
+	mf, err := manifest.Load(manifestPath)
+	if err != nil {
+		log.WithError(err).Errorf("failed to load manifest '%s'", manifestPath)
+		return err
+	}
+	side, err := manifest.ParseSide(c.String(flagSide.Name))
+	if err != nil {
+		return err
+	}
+	outDir := c.Path(flagDirectory.Name)
+	optional := c.Bool(flagWithOptional.Name)
+	backend := api.BackendFromContext(ctx)
+
+	lockPath := manifest.LockPath(manifestPath)
+	if !c.Bool(flagReResolve.Name) {
+		lock, err := manifest.LoadLock(lockPath)
+		switch {
+		case os.IsNotExist(err):
+			log.Debugf("no lockfile at '%s'", lockPath)
+		case err != nil:
+			log.WithError(err).Errorf("failed to load lockfile '%s'", lockPath)
+			return err
+		case lock.Backend != backend.Name() || !lock.Matches(mf, side, optional):
+			log.Infof("lockfile '%s' is out of date, resolving again", lockPath)
+		default:
+			log.Infof("installing %d locked files from '%s'", len(lock.Mods), lockPath)
+			return downloadFiles(ctx, lockedFiles(lock), "", outDir, c.Bool(flagForce.Name))
+		}
+	}
+
+	filter, err := manifestFilter(ctx, mf, nil)
+	if err != nil {
+		return err
+	}
+	roots, err := manifestRoots(ctx, backend, mf, side)
+	if err != nil {
+		return err
+	}
+	log.Infof("installing %d mods from '%s'", len(roots), manifestPath)
+
+	res := &resolver.Resolver{Backend: backend, ListFiles: filter.listFiles}
+	if optional {
+		res.Follow = resolver.FollowOptional
+	}
+	plan, err := res.Resolve(ctx, roots...)
+	if err != nil {
+		return err
+	}
+	log.Debugf("found an additional %d files", len(plan.Mods)-len(roots))
+
+	err = downloadFiles(ctx, plan.Files(), "", outDir, c.Bool(flagForce.Name))
+	if err != nil {
+		return err
+	}
+
+	lock := newLock(backend.Name(), mf, side, optional, plan)
+	err = lock.Save(lockPath)
+	if err != nil {
+		log.WithError(err).Errorf("failed to write lockfile '%s'", lockPath)
+		return err
+	}
+	log.Debugf("wrote lockfile '%s'", lockPath)
+	return nil
+}
+
+// manifestFilter builds the ModFilter for a mod in a manifest, or the filter
+// for the whole manifest if mod is nil
+func manifestFilter(ctx context.Context, mf *manifest.Manifest, mod *manifest.Mod) (*ModFilter, error) {
+	return newModFilter(ctx, mf.ReleaseOf(mod), mf.GameVersion, mf.Loader)
+}
+
+// manifestRoots looks up every mod in the manifest needed on the given side,
+// concurrently, returning them as the roots of a dependency graph
+func manifestRoots(ctx context.Context, backend api.Backend,
+	mf *manifest.Manifest, side manifest.Side) ([]resolver.Root, error) {
+
+	log := modlog.FromContext(ctx)
+
+	mods := make([]manifest.Mod, 0, len(mf.Mods))
+	for _, mod := range mf.Mods {
+		if mod.Side.Includes(side) {
+			mods = append(mods, mod)
+		} else {
+			log.WithField("mod", mod.Ref()).
+				Debugf("skipping mod only needed on the %s", mod.Side)
+		}
+	}
+
+	roots := make([]resolver.Root, len(mods))
+	errs := make([]error, len(mods))
+	wg := new(sync.WaitGroup)
+	for idx, mod := range mods {
+		wg.Add(1)
+		go func(idx int, mod manifest.Mod) {
+			defer wg.Done()
+			ctx, log := modlog.SetContextLogger(ctx, log.WithField("mod", mod.Ref()))
+
+			filter, err := manifestFilter(ctx, mf, &mod)
+			if err != nil {
+				errs[idx] = err
+				return
+			}
+			addon, err := backend.Lookup(ctx, mod.Ref())
+			if err == nil && addon == nil {
+				err = &api.ErrNoSuchAddon{Name: mod.Slug, ID: mod.ID}
+			}
+			if err != nil {
+				log.WithError(err).Error("failed to find mod")
+				errs[idx] = err
+				return
+			}
+			roots[idx] = resolver.Root{
+				ModID:     addon.ID,
+				Addon:     addon,
+				FileID:    mod.FileID,
+				ListFiles: filter.listFiles,
+			}
+		}(idx, mod)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return roots, nil
+}
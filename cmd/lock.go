@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/frebib/mcmod/api"
+	modlog "github.com/frebib/mcmod/log"
+	"github.com/frebib/mcmod/manifest"
+	"github.com/frebib/mcmod/resolver"
+)
+
+// loadManifestLock loads a manifest and its lockfile, which must exist and
+// have been resolved with the current backend
+func loadManifestLock(ctx context.Context, manifestPath string) (*manifest.Manifest, *manifest.Lock, error) {
+	log := modlog.FromContext(ctx)
+
+	mf, err := manifest.Load(manifestPath)
+	if err != nil {
+		log.WithError(err).Errorf("failed to load manifest '%s'", manifestPath)
+		return nil, nil, err
+	}
+	lockPath := manifest.LockPath(manifestPath)
+	lock, err := manifest.LoadLock(lockPath)
+	if os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("no lockfile at '%s', run install first", lockPath)
+	} else if err != nil {
+		log.WithError(err).Errorf("failed to load lockfile '%s'", lockPath)
+		return nil, nil, err
+	}
+	if name := api.BackendFromContext(ctx).Name(); lock.Backend != name {
+		return nil, nil, fmt.Errorf("lockfile '%s' was resolved with backend '%s', not '%s'",
+			lockPath, lock.Backend, name)
+	}
+	return mf, lock, nil
+}
+
+// manifestMod returns the mod in the manifest that a locked mod was resolved
+// from, or nil if it is a dependency
+func manifestMod(mf *manifest.Manifest, locked *manifest.LockedMod) *manifest.Mod {
+	if !locked.Root {
+		return nil
+	}
+	if mod := mf.Find(strconv.Itoa(locked.ID)); mod != nil {
+		return mod
+	}
+	if locked.Slug != "" {
+		return mf.Find(locked.Slug)
+	}
+	return nil
+}
+
+// newLock records the files chosen by an install plan for a manifest
+func newLock(backend string, mf *manifest.Manifest, side manifest.Side, optional bool, plan *resolver.Plan) *manifest.Lock {
+	lock := &manifest.Lock{
+		Backend:     backend,
+		GameVersion: mf.GameVersion,
+		Loader:      mf.Loader,
+		Side:        side,
+		Optional:    optional,
+		Mods:        make([]manifest.LockedMod, len(plan.Mods)),
+	}
+	for idx, mod := range plan.Mods {
+		locked := &lock.Mods[idx]
+		locked.ID = mod.ID
+		locked.Root = mod.Root
+		if mod.Addon != nil {
+			locked.Slug = mod.Addon.Slug
+		}
+		locked.Release = mf.ReleaseOf(manifestMod(mf, locked))
+		locked.Dependencies = mod.Dependencies
+		setLockedFile(locked, mod.File)
+	}
+	return lock
+}
+
+// setLockedFile records the file to install for a locked mod
+func setLockedFile(locked *manifest.LockedMod, file *api.File) {
+	locked.FileID = file.ID
+	locked.FileName = file.FileName
+	locked.FileDate = file.FileDate
+	locked.FileRelease = file.ReleaseType.String()
+	locked.DownloadURL = file.DownloadURL
+	locked.Size = file.FileLength
+	locked.Fingerprint = file.PackageFingerprint
+	locked.Hashes = file.Hashes
+}
+
+// lockedFile returns the file recorded for a locked mod
+func lockedFile(mod *manifest.LockedMod) *api.File {
+	return &api.File{
+		ID:                 mod.FileID,
+		ModID:              mod.ID,
+		FileName:           mod.FileName,
+		FileDate:           mod.FileDate,
+		ReleaseType:        api.ParseReleaseType(mod.FileRelease),
+		DownloadURL:        mod.DownloadURL,
+		FileLength:         mod.Size,
+		PackageFingerprint: mod.Fingerprint,
+		Hashes:             mod.Hashes,
+	}
+}
+
+// lockedFiles returns every file recorded in a lockfile, in install order
+func lockedFiles(lock *manifest.Lock) []*api.File {
+	files := make([]*api.File, len(lock.Mods))
+	for idx := range lock.Mods {
+		files[idx] = lockedFile(&lock.Mods[idx])
+	}
+	return files
+}
@@ -2,21 +2,62 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"os"
 	"sort"
 
 	"github.com/frebib/mcmod/api"
+	"github.com/frebib/mcmod/download"
 	modlog "github.com/frebib/mcmod/log"
+	mc "github.com/frebib/mcmod/minecraft"
+	"github.com/frebib/mcmod/util"
 )
 
 type ModFilter struct {
 	Release api.ReleaseType
 	Version string
+	Loader  string
+}
+
+// newModFilter parses a ModFilter from the textual
+// options given on the command-line or in a manifest
+func newModFilter(ctx context.Context, release, version, loader string) (*ModFilter, error) {
+	reqRelease := api.ParseReleaseType(release)
+	if reqRelease == api.ReleaseUnknown {
+		return nil, fmt.Errorf("invalid release type '%s'", release)
+	}
+	version, err := resolveGameVersion(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+	return &ModFilter{Release: reqRelease, Version: version, Loader: loader}, nil
+}
+
+// resolveGameVersion replaces any aliases in a game version or constraint,
+// such as "latest-release", with the versions they refer to
+func resolveGameVersion(ctx context.Context, version string) (string, error) {
+	if !mc.HasAlias(version) {
+		return version, nil
+	}
+	manifest, err := mc.ManifestFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	resolved := manifest.ExpandAliases(version)
+	modlog.FromContext(ctx).Debugf("resolved game version '%s' to '%s'", version, resolved)
+	return resolved, nil
+}
+
+// listFiles lists the files of a mod that match the filter, as a
+// resolver.FileLister
+func (f *ModFilter) listFiles(ctx context.Context, modID int) (api.Files, error) {
+	return listFilterMods(ctx, modID, f)
 }
 
 func listFilterMods(ctx context.Context, modID int, filter *ModFilter) (api.Files, error) {
 	log := modlog.FromContext(ctx)
 
-	files, err := api.ClientFromContext(ctx).Files(ctx, modID)
+	files, err := api.BackendFromContext(ctx).Files(ctx, modID)
 	if err != nil {
 		log.WithError(err).Errorf("failed to list mod files")
 		return nil, err
@@ -60,6 +101,16 @@ func modFilter(ctx context.Context, files api.Files, reqFilter *ModFilter) (api.
 		filters = append(filters, versionFilter)
 	}
 
+	if reqFilter.Loader != "" {
+		log := log.WithField("loader", reqFilter.Loader)
+		loaderFilter := api.FileFilterLoader(reqFilter.Loader)
+		loaderFilter.AfterFunc = func(files api.Files) error {
+			log.Debugf("%d files match loader filter", len(files))
+			return nil
+		}
+		filters = append(filters, loaderFilter)
+	}
+
 	// Apply requested filters
 	if len(filters) > 0 {
 		var err error
@@ -70,3 +121,53 @@ func modFilter(ctx context.Context, files api.Files, reqFilter *ModFilter) (api.
 	}
 	return files, nil
 }
+
+// downloadFiles downloads each file into outDir, using the name of the file,
+// or to outFile if given, which is only allowed for a single file. Files are
+// downloaded concurrently, and every file is attempted even if some fail.
+// Files already present and valid are skipped, unless force is set
+func downloadFiles(ctx context.Context, files []*api.File, outFile, outDir string, force bool) error {
+	log := modlog.FromContext(ctx)
+	backend := api.BackendFromContext(ctx)
+
+	// Files are downloaded concurrently, so they can't all share one path
+	if outFile != "" && len(files) > 1 {
+		return util.ErrMultipleOutputs
+	}
+
+	jobs := make([]*download.Job, len(files))
+	for idx, dl := range files {
+		if dl.DownloadURL == "" {
+			return &api.ErrNoDownloadURL{ModID: dl.ModID, FileName: dl.FileName}
+		}
+		// Calculate final path+filename for mod output
+		filePath, err := util.CalcFilePath(dl.FileName, outFile, outDir)
+		if err != nil {
+			return err
+		}
+
+		job := &download.Job{URL: dl.DownloadURL, Path: filePath, Force: force}
+		// Files written to stdout can't be read back to verify them
+		if outFile != "-" {
+			job.Expect = download.ExpectFile(dl)
+		}
+		if dl.ModID != 0 && dl.ID != 0 {
+			job.Key = download.FileKey(backend.Name(), dl.ModID, dl.ID)
+		}
+		jobs[idx] = job
+	}
+
+	progress := download.NewProgress(ctx, os.Stderr)
+	progress.Start()
+	summary := download.ManagerFromContext(ctx).
+		Run(context.WithValue(ctx, download.ProgressKey, progress), jobs)
+	progress.Stop()
+
+	log.Infof("downloaded %d files: %d succeeded, %d up to date, %d failed, %d skipped",
+		len(jobs), len(summary.Succeeded), len(summary.UpToDate),
+		len(summary.Failed), len(summary.Skipped))
+	if err := summary.Err(); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	modlog "github.com/frebib/mcmod/log"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+var (
+	Outdated = &cli.Command{
+		Name:      "outdated",
+		Usage:     "list installed mods with newer files available",
+		Action:    cmdDoOutdated,
+		ArgsUsage: "[name|id...]",
+		Flags: []cli.Flag{
+			&flagManifest,
+			&flagFormat,
+		},
+	}
+)
+
+// outdatedFile describes a file in the JSON output of outdated
+type outdatedFile struct {
+	ID       int       `json:"id"`
+	FileName string    `json:"fileName"`
+	FileDate time.Time `json:"fileDate"`
+	Release  string    `json:"releaseType"`
+}
+
+type outdatedMod struct {
+	ID      int          `json:"id"`
+	Slug    string       `json:"slug,omitempty"`
+	Current outdatedFile `json:"current"`
+	Latest  outdatedFile `json:"latest"`
+}
+
+// cmdDoOutdated lists the locked mods that have newer files, exiting with a
+// non-zero status if there are any, so it can be used to alert on updates
+func cmdDoOutdated(c *cli.Context) error {
+	ctx := c.Context
+	log := modlog.FromContext(ctx)
+
+	format := c.String(flagFormat.Name)
+	if format != formatTable && format != formatJSON {
+		return fmt.Errorf("invalid output format '%s'", format)
+	}
+
+	mf, lock, err := loadManifestLock(ctx, c.Path(flagManifest.Name))
+	if err != nil {
+		return err
+	}
+	updates, err := findUpdates(ctx, mf, lock, c.Args().Slice())
+	if err != nil {
+		return err
+	}
+
+	if format == formatJSON {
+		err = printUpdatesJSON(os.Stdout, updates)
+	} else if len(updates) > 0 {
+		err = printUpdates(os.Stdout, updates)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(updates) < 1 {
+		log.Info("all mods are up to date")
+		return nil
+	}
+	log.Debugf("%d mods are outdated", len(updates))
+	return cli.Exit("", 1)
+}
+
+// printUpdatesJSON writes the current and newer file of each mod as JSON
+func printUpdatesJSON(out io.Writer, updates []modUpdate) error {
+	mods := make([]outdatedMod, len(updates))
+	for idx, update := range updates {
+		mods[idx] = outdatedMod{
+			ID:   update.Mod.ID,
+			Slug: update.Mod.Slug,
+			Current: outdatedFile{
+				ID:       update.Mod.FileID,
+				FileName: update.Mod.FileName,
+				FileDate: update.Mod.FileDate,
+				Release:  update.Mod.FileRelease,
+			},
+			Latest: outdatedFile{
+				ID:       update.File.ID,
+				FileName: update.File.FileName,
+				FileDate: update.File.FileDate,
+				Release:  update.File.ReleaseType.String(),
+			},
+		}
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(mods)
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	modlog "github.com/frebib/mcmod/log"
+	"github.com/frebib/mcmod/manifest"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	Remove = &cli.Command{
+		Name:      "remove",
+		Usage:     "remove a mod, and the dependencies installed only for it",
+		Action:    cmdDoRemove,
+		ArgsUsage: "<name|id>",
+		Flags: []cli.Flag{
+			&flagManifest,
+			&flagDirectory,
+			&flagKeepDeps,
+		},
+	}
+)
+
+func cmdDoRemove(c *cli.Context) error {
+	ctx := c.Context
+	log := modlog.FromContext(ctx)
+
+	if c.NArg() < 1 {
+		log.Error("missing required arg: " + c.Command.ArgsUsage)
+		return cli.ShowSubcommandHelp(c)
+	}
+	ref := c.Args().First()
+
+	manifestPath := c.Path(flagManifest.Name)
+	mf, lock, err := loadManifestLock(ctx, manifestPath)
+	if err != nil {
+		return err
+	}
+
+	// The mod may be listed by slug in the manifest, but removed by id
+	locked := findLockedMod(lock, ref)
+	mfMod := mf.Find(ref)
+	if mfMod == nil && locked != nil {
+		mfMod = manifestMod(mf, locked)
+	}
+	if mfMod == nil && locked == nil {
+		return fmt.Errorf("mod '%s' is not installed", ref)
+	}
+	if mfMod != nil {
+		mf.Remove(mfMod.Ref())
+	}
+
+	var remove []int
+	if locked != nil {
+		if dependents := lock.Dependents(locked.ID); len(dependents) > 0 {
+			names := make([]string, len(dependents))
+			for idx, id := range dependents {
+				names[idx] = lock.Find(id).Name()
+			}
+			if !locked.Root {
+				return fmt.Errorf("mod '%s' is needed by %s", ref, strings.Join(names, ", "))
+			}
+			// Keep the mod installed for the mods that need it
+			log.WithField("mod", locked.Name()).
+				Warnf("mod is still needed by %s, keeping it as a dependency",
+					strings.Join(names, ", "))
+			locked.Root = false
+		} else {
+			remove = append(remove, locked.ID)
+			if !c.Bool(flagKeepDeps.Name) {
+				remove = append(remove, lock.Orphans(locked.ID)...)
+			}
+		}
+
+		outDir := c.Path(flagDirectory.Name)
+		for _, id := range remove {
+			mod := lock.Find(id)
+			path := filepath.Join(outDir, mod.FileName)
+			err := os.Remove(path)
+			if err != nil && !os.IsNotExist(err) {
+				log.WithError(err).Errorf("failed to remove file '%s'", path)
+				return err
+			}
+			log.WithField("mod", mod.Name()).Infof("removed file '%s'", path)
+		}
+		lock.Remove(remove...)
+	}
+
+	err = mf.Save(manifestPath)
+	if err != nil {
+		log.WithError(err).Errorf("failed to write manifest '%s'", manifestPath)
+		return err
+	}
+	lockPath := manifest.LockPath(manifestPath)
+	err = lock.Save(lockPath)
+	if err != nil {
+		log.WithError(err).Errorf("failed to write lockfile '%s'", lockPath)
+		return err
+	}
+	return nil
+}
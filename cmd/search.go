@@ -9,6 +9,7 @@ import (
 	"github.com/dustin/go-humanize"
 	"github.com/frebib/mcmod/api"
 	modlog "github.com/frebib/mcmod/log"
+	mc "github.com/frebib/mcmod/minecraft"
 	"github.com/frebib/mcmod/util"
 	"github.com/urfave/cli/v2"
 )
@@ -27,6 +28,16 @@ var (
 		Aliases: []string{"a"},
 		Value:   false,
 	}
+	flagPage = cli.UintFlag{
+		Name:  "page",
+		Usage: "page of results to start from, counting from 1",
+		Value: 1,
+	}
+	flagPageSize = cli.UintFlag{
+		Name:        "page-size",
+		Usage:       "number of results to fetch in each request",
+		DefaultText: "--count",
+	}
 	Search = &cli.Command{
 		Name:      "search",
 		Usage:     "search for a mod",
@@ -35,6 +46,8 @@ var (
 		Flags: []cli.Flag{
 			&flagAll,
 			&flagCount,
+			&flagPage,
+			&flagPageSize,
 			&flagVersion,
 		},
 	}
@@ -49,13 +62,46 @@ func cmdDoModSearch(c *cli.Context) (err error) {
 		return cli.ShowSubcommandHelp(c)
 	}
 
+	showAll := c.Bool(flagAll.Name)
+	count := c.Uint(flagCount.Name)
+	pageSize := c.Uint(flagPageSize.Name)
+	if pageSize == 0 {
+		pageSize = count
+		if showAll || pageSize > api.DefaultPageSize {
+			pageSize = api.DefaultPageSize
+		}
+	}
+	page := c.Uint(flagPage.Name)
+	if page < 1 {
+		page = 1
+	}
+
+	// Results are filtered by game version here, as the backends only match
+	// versions exactly, not constraints. Anything that isn't a constraint is
+	// passed to the backend to match as it is
+	var constraint *mc.Constraint
+	var literalVer string
+	gameVer, err := resolveGameVersion(ctx, c.String(flagVersion.Name))
+	if err != nil {
+		return err
+	}
+	if gameVer != "" {
+		constraint, err = mc.ParseConstraint(gameVer)
+		if err != nil {
+			log.Debugf("matching game version '%s' literally: %s", gameVer, err)
+			literalVer = gameVer
+		}
+	}
+
 	term := strings.Join(c.Args().Slice(), " ")
-	results, err := api.ClientFromContext(ctx).AddonSearch(ctx,
+	results := api.NewSearchIterator(api.BackendFromContext(ctx),
 		api.AddonSearchOption{
 			GameId:      api.GameMinecraft,
 			Sort:        api.AddonSortPopularity,
-			GameVersion: c.String(flagVersion.Name),
+			GameVersion: literalVer,
 			Filter:      term,
+			Index:       int((page - 1) * pageSize),
+			PageSize:    int(pageSize),
 		},
 	)
 
@@ -64,7 +110,13 @@ func cmdDoModSearch(c *cli.Context) (err error) {
 	fmt.Fprint(w, "ID\tName\tDownloads\tLast Updated\tSlug\tVersions\n")
 
 	// Attempt to do a better search, with a fuzzy search library
-	for idx, mod := range results {
+	// Only show the max amount of results, if not displaying all
+	for shown := uint(0); (showAll || shown < count) && results.Next(ctx); {
+		mod := results.Addon()
+		if constraint != nil && !supportsVersion(mod, constraint) {
+			continue
+		}
+		shown++
 		versions := mod.SupportedVersions().LatestPatches().Strings()
 		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
 			mod.ID, util.EllipsiseString(mod.Name, 32),
@@ -73,10 +125,20 @@ func cmdDoModSearch(c *cli.Context) (err error) {
 			mod.Slug,
 			strings.Join(versions, ", "),
 		)
-		// Only show the max amount of results, if not displaying all
-		if !c.Bool(flagAll.Name) && uint(idx) >= c.Uint(flagCount.Name)-1 {
-			break
-		}
+	}
+	if err := results.Err(); err != nil {
+		return err
 	}
 	return w.Flush()
 }
+
+// supportsVersion returns true if a mod supports any game
+// version that matches the constraint
+func supportsVersion(mod *api.Addon, constraint *mc.Constraint) bool {
+	for _, ver := range mod.SupportedVersions() {
+		if constraint.Check(ver) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/frebib/mcmod/api"
+	modlog "github.com/frebib/mcmod/log"
+	"github.com/frebib/mcmod/manifest"
+	"github.com/frebib/mcmod/resolver"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	Update = &cli.Command{
+		Name:      "update",
+		Usage:     "upgrade installed mods to the newest matching files",
+		Action:    cmdDoUpdate,
+		ArgsUsage: "[name|id...]",
+		Flags: []cli.Flag{
+			&flagManifest,
+			&flagDirectory,
+			&flagDryRun,
+		},
+	}
+)
+
+// dateFormat is the format of file dates shown in tables
+const dateFormat = "2006-01-02"
+
+// modUpdate is a newer file for a locked mod
+type modUpdate struct {
+	Mod  *manifest.LockedMod
+	File *api.File
+}
+
+func cmdDoUpdate(c *cli.Context) error {
+	ctx := c.Context
+	log := modlog.FromContext(ctx)
+
+	manifestPath := c.Path(flagManifest.Name)
+	mf, lock, err := loadManifestLock(ctx, manifestPath)
+	if err != nil {
+		return err
+	}
+	updates, err := findUpdates(ctx, mf, lock, c.Args().Slice())
+	if err != nil {
+		return err
+	}
+	if len(updates) < 1 {
+		log.Info("all mods are up to date")
+		return nil
+	}
+
+	err = printUpdates(os.Stdout, updates)
+	if err != nil || c.Bool(flagDryRun.Name) {
+		return err
+	}
+
+	lockPath := manifest.LockPath(manifestPath)
+	outDir := c.Path(flagDirectory.Name)
+	for _, update := range updates {
+		ctx, log := modlog.SetContextLogger(ctx, log.WithField("mod", update.Mod.Name()))
+		err := applyUpdate(ctx, lock, update, outDir)
+		if err != nil {
+			return err
+		}
+		// Save after every update, so the lockfile always matches the
+		// files on disk, even if a later update fails
+		err = lock.Save(lockPath)
+		if err != nil {
+			log.WithError(err).Errorf("failed to write lockfile '%s'", lockPath)
+			return err
+		}
+	}
+	log.Infof("updated %d mods", len(updates))
+	return nil
+}
+
+// findUpdates finds the newest file for each locked mod, matching the filters
+// it was resolved with, and returns those newer than the locked file. If refs
+// is not empty, only the mods with the given slugs or ids are checked
+func findUpdates(ctx context.Context, mf *manifest.Manifest, lock *manifest.Lock, refs []string) ([]modUpdate, error) {
+	log := modlog.FromContext(ctx)
+
+	mods, err := selectLockedMods(lock, refs)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]*api.File, len(mods))
+	errs := make([]error, len(mods))
+	wg := new(sync.WaitGroup)
+	for idx, mod := range mods {
+		ctx, log := modlog.SetContextLogger(ctx, log.WithField("mod", mod.Name()))
+		if mfMod := manifestMod(mf, mod); mfMod != nil && mfMod.FileID != 0 {
+			log.Debugf("skipping mod pinned to file %d", mfMod.FileID)
+			continue
+		}
+		filter, err := newModFilter(ctx, mod.Release, lock.GameVersion, lock.Loader)
+		if err != nil {
+			errs[idx] = err
+			continue
+		}
+
+		wg.Add(1)
+		go func(idx int, mod *manifest.LockedMod) {
+			defer wg.Done()
+			latest, err := listFilterMods(ctx, mod.ID, filter)
+			if err != nil {
+				errs[idx] = err
+				return
+			}
+			if len(latest) > 0 && latest[0].ID != mod.FileID &&
+				latest[0].FileDate.After(mod.FileDate) {
+				files[idx] = &latest[0]
+			}
+		}(idx, mod)
+	}
+	wg.Wait()
+
+	var updates []modUpdate
+	for idx, mod := range mods {
+		if errs[idx] != nil {
+			return nil, errs[idx]
+		}
+		if files[idx] != nil {
+			updates = append(updates, modUpdate{Mod: mod, File: files[idx]})
+		}
+	}
+	return updates, nil
+}
+
+// selectLockedMods returns the locked mods with the given slugs or ids, or
+// every locked mod if refs is empty
+func selectLockedMods(lock *manifest.Lock, refs []string) ([]*manifest.LockedMod, error) {
+	var mods []*manifest.LockedMod
+	if len(refs) < 1 {
+		for idx := range lock.Mods {
+			mods = append(mods, &lock.Mods[idx])
+		}
+		return mods, nil
+	}
+	for _, ref := range refs {
+		mod := findLockedMod(lock, ref)
+		if mod == nil {
+			return nil, fmt.Errorf("mod '%s' is not installed", ref)
+		}
+		mods = append(mods, mod)
+	}
+	return mods, nil
+}
+
+// findLockedMod returns the locked mod with the given slug or id, or nil
+func findLockedMod(lock *manifest.Lock, ref string) *manifest.LockedMod {
+	for idx, mod := range lock.Mods {
+		if strings.EqualFold(mod.Slug, ref) || strconv.Itoa(mod.ID) == ref {
+			return &lock.Mods[idx]
+		}
+	}
+	return nil
+}
+
+// applyUpdate downloads the new file for a mod, removes the file it replaces
+// and records the new file in the lockfile
+func applyUpdate(ctx context.Context, lock *manifest.Lock, update modUpdate, outDir string) error {
+	log := modlog.FromContext(ctx)
+
+	err := downloadFiles(ctx, []*api.File{update.File}, "", outDir, false)
+	if err != nil {
+		return err
+	}
+	if update.File.FileName != update.Mod.FileName {
+		oldPath := filepath.Join(outDir, update.Mod.FileName)
+		err = os.Remove(oldPath)
+		if err != nil && !os.IsNotExist(err) {
+			log.WithError(err).Errorf("failed to remove old file '%s'", oldPath)
+			return err
+		}
+		log.Debugf("removed old file '%s'", oldPath)
+	}
+
+	// The new file may depend on different mods, but only follow those
+	// that are already installed. Anything else needs resolving again
+	deps, err := api.BackendFromContext(ctx).Dependencies(ctx, update.File)
+	if err != nil {
+		log.WithError(err).Error("failed to list dependencies")
+		return err
+	}
+	var depIDs []int
+	for _, dep := range deps {
+		if !resolver.FollowOptional(dep) {
+			continue
+		}
+		if lock.Find(dep.AddonID) != nil {
+			depIDs = append(depIDs, dep.AddonID)
+		} else if resolver.FollowRequired(dep) {
+			log.Warnf("new file requires mod %d, which isn't installed. "+
+				"Run install with --%s to install it", dep.AddonID, flagReResolve.Name)
+		}
+	}
+
+	update.Mod.Dependencies = depIDs
+	setLockedFile(update.Mod, update.File)
+	log.WithField("file", update.File.FileName).Info("updated mod")
+	return nil
+}
+
+// printUpdates writes a table of the current and newer file of each mod
+func printUpdates(out io.Writer, updates []modUpdate) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprint(w, "Mod\tCurrent\tDate\tRelease\tLatest\tDate\tRelease\n")
+	for _, update := range updates {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			update.Mod.Name(),
+			update.Mod.FileName, update.Mod.FileDate.Format(dateFormat),
+			update.Mod.FileRelease,
+			update.File.FileName, update.File.FileDate.Format(dateFormat),
+			update.File.ReleaseType.String(),
+		)
+	}
+	return w.Flush()
+}
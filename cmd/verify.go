@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/frebib/mcmod/download"
+	modlog "github.com/frebib/mcmod/log"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	Verify = &cli.Command{
+		Name:   "verify",
+		Usage:  "check installed files against the lockfile",
+		Action: cmdDoVerify,
+		Flags: []cli.Flag{
+			&flagManifest,
+			&flagDirectory,
+		},
+	}
+)
+
+func cmdDoVerify(c *cli.Context) error {
+	ctx := c.Context
+	log := modlog.FromContext(ctx)
+
+	_, lock, err := loadManifestLock(ctx, c.Path(flagManifest.Name))
+	if err != nil {
+		return err
+	}
+
+	outDir := c.Path(flagDirectory.Name)
+	failed := 0
+	for idx := range lock.Mods {
+		mod := &lock.Mods[idx]
+		log := log.WithField("mod", mod.Name())
+		path := filepath.Join(outDir, mod.FileName)
+		err := download.Verify(path, download.ExpectFile(lockedFile(mod)))
+		if err != nil {
+			log.WithError(err).Error("file failed verification")
+			failed++
+			continue
+		}
+		log.WithField("file", mod.FileName).Debug("file is valid")
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files failed verification", failed, len(lock.Mods))
+	}
+	log.Infof("all %d files are valid", len(lock.Mods))
+	return nil
+}
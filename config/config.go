@@ -0,0 +1,110 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+type contextKey string
+
+// ContextKey is the key used to identify a Config inside a context.Context
+const ContextKey contextKey = "config"
+
+// FileName is the name of the config file inside the user config directory
+const FileName = "config.toml"
+
+// Config holds the user settings read from the config file. Every setting is
+// optional and command-line flags take precedence over any set here
+type Config struct {
+	// Backend is the name of the default mod source
+	Backend string `toml:"backend"`
+
+	CurseForge CurseForgeConfig `toml:"curseforge"`
+	Cache      CacheConfig      `toml:"cache"`
+	HTTP       HTTPConfig       `toml:"http"`
+	Download   DownloadConfig   `toml:"download"`
+	Minecraft  MinecraftConfig  `toml:"minecraft"`
+}
+
+type CurseForgeConfig struct {
+	// ApiKey is the key used to authenticate with the CurseForge API
+	ApiKey string `toml:"api-key"`
+}
+
+type CacheConfig struct {
+	// Dir is the directory that API responses are cached in
+	Dir string `toml:"dir"`
+	// TTL is how long cached API responses are used before revalidating them
+	TTL Duration `toml:"ttl"`
+}
+
+type HTTPConfig struct {
+	// Retries is the number of times a failed API request is retried
+	Retries *int `toml:"retries"`
+	// RateLimit is the number of API requests permitted per second
+	RateLimit *float64 `toml:"rate-limit"`
+	// RateBurst is the number of API requests permitted at once
+	RateBurst *int `toml:"rate-burst"`
+}
+
+type DownloadConfig struct {
+	// Jobs is the number of files downloaded at once
+	Jobs int `toml:"jobs"`
+	// Store is how files are installed from the shared download store, or
+	// "off" to bypass it
+	Store string `toml:"store"`
+	// StoreDir is the directory that downloaded files are stored in
+	StoreDir string `toml:"store-dir"`
+}
+
+type MinecraftConfig struct {
+	// Manifest is the path or URL of the game version manifest, instead of
+	// fetching it from Mojang
+	Manifest string `toml:"manifest"`
+}
+
+// Duration is a time.Duration that can be decoded from
+// a duration string in the config file, such as "1h30m"
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalText(text []byte) (err error) {
+	d.Duration, err = time.ParseDuration(string(text))
+	return err
+}
+
+// DefaultPath returns the path to the config file in the user config directory
+// e.g. ~/.config/mcmod/config.toml
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "mcmod", FileName), nil
+}
+
+// Load reads the config file at path. A missing file is not an error, as the
+// config file is optional, and an empty Config is returned instead
+func Load(path string) (*Config, error) {
+	conf := new(Config)
+	_, err := toml.DecodeFile(path, conf)
+	if os.IsNotExist(err) {
+		return conf, nil
+	}
+	return conf, err
+}
+
+// FromContext loads the Config from a context, or returns
+// an empty Config if none is found in the context
+func FromContext(ctx context.Context) *Config {
+	conf, ok := ctx.Value(ContextKey).(*Config)
+	if !ok {
+		return new(Config)
+	}
+	return conf
+}
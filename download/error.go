@@ -0,0 +1,37 @@
+package download
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrVerify is returned when a file doesn't match the file that was expected
+type ErrVerify struct {
+	Path string
+	// Check is the property of the file that didn't match, such as its size
+	// or the name of a hash algorithm
+	Check    string
+	Expected string
+	Actual   string
+}
+
+func (e *ErrVerify) Error() string {
+	return fmt.Sprintf("file '%s' failed verification: expected %s %s, got %s",
+		e.Path, e.Check, e.Expected, e.Actual)
+}
+
+// ErrDownloads is returned when one or more of a set of downloads fails
+type ErrDownloads struct {
+	Errs []error
+}
+
+func (e *ErrDownloads) Error() string {
+	if len(e.Errs) == 1 {
+		return e.Errs[0].Error()
+	}
+	msgs := make([]string, len(e.Errs))
+	for idx, err := range e.Errs {
+		msgs[idx] = err.Error()
+	}
+	return fmt.Sprintf("%d downloads failed: %s", len(e.Errs), strings.Join(msgs, "; "))
+}
@@ -3,7 +3,9 @@ package download
 import (
 	"context"
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/dustin/go-humanize"
@@ -11,17 +13,91 @@ import (
 	git_humanize "github.com/git-lfs/git-lfs/tools/humanize"
 )
 
-func FileFromURL(ctx context.Context, url, path string) error {
-	rd, err := FromURL(ctx, nil, url)
+const (
+	// PartSuffix is appended to the name of a file while it is being
+	// downloaded
+	PartSuffix = ".part"
+	// validatorSuffix is appended to the name of a partial file to store the
+	// validator needed to resume downloading it
+	validatorSuffix = ".validator"
+)
+
+// FileFromURL downloads the file at url to path, resuming any earlier partial
+// download of it if possible
+func FileFromURL(ctx context.Context, url, path string, expect *Expect) error {
+	// Special files, such as /dev/stdout, are written directly so can't be
+	// resumed, and have nowhere to keep a partial download
+	if info, err := os.Stat(path); err == nil && !info.Mode().IsRegular() {
+		rd, _, err := ResumeURL(ctx, nil, url, 0, "")
+		if err != nil {
+			if rd != nil {
+				rd.Close()
+			}
+			return err
+		}
+		return File(ctx, rd, path, expect)
+	}
+
+	offset, validator := partialDownload(path)
+	rd, validator, err := ResumeURL(ctx, nil, url, offset, validator)
 	if err != nil {
+		if rd != nil {
+			rd.Close()
+		}
 		return err
 	}
-	return File(ctx, rd, path)
+
+	// Remember how to resume the download, in case it fails
+	validatorPath := PartPath(path) + validatorSuffix
+	if rd.Resumed() == 0 {
+		if validator != "" {
+			err = ioutil.WriteFile(validatorPath, []byte(validator), 0644)
+		} else {
+			err = os.Remove(validatorPath)
+		}
+		if err != nil && !os.IsNotExist(err) {
+			rd.Close()
+			return err
+		}
+	}
+	return File(ctx, rd, path, expect)
+}
+
+// partialDownload returns the size of the partial download of the file at
+// path, and the validator to resume it with, or zero if it can't be resumed
+func partialDownload(path string) (int64, string) {
+	partPath := PartPath(path)
+	info, err := os.Stat(partPath)
+	if err != nil || !info.Mode().IsRegular() {
+		return 0, ""
+	}
+	validator, err := ioutil.ReadFile(partPath + validatorSuffix)
+	if err != nil || len(validator) < 1 {
+		return 0, ""
+	}
+	return info.Size(), string(validator)
+}
+
+// PartPath returns the path that the file at path is written to while it is
+// being downloaded. It is hidden, and doesn't end in .jar, so that the game
+// won't try to load it
+func PartPath(path string) string {
+	dir, name := filepath.Split(path)
+	return filepath.Join(dir, "."+name+PartSuffix)
 }
 
-func File(ctx context.Context, src io.ReadCloser, path string) error {
+// File writes everything from src into the file at path. The file is written
+// to a temporary file alongside it and only renamed into place once complete
+// so that an existing file is never left half-written. If expect is not nil,
+// the written file is verified against it before being renamed.
+//
+// If src is a ReadCounter that was resumed, it is appended to the existing
+// partial file. If the download fails, the partial file is kept to be resumed
+// later, if the server allows it
+func File(ctx context.Context, src io.ReadCloser, path string, expect *Expect) error {
 	log := modlog.FromContext(ctx).
 		WithField("name", path)
+	defer src.Close()
 
 	srcCount, isCounter := src.(ReadCounter)
 	if isCounter {
@@ -29,28 +105,112 @@ func File(ctx context.Context, src io.ReadCloser, path string) error {
 	}
 
 	log.Info("downloading file")
+	if progress := ProgressFromContext(ctx); progress != nil && isCounter {
+		bar := progress.Add(filepath.Base(path), srcCount)
+		defer bar.Done()
+	}
 
-	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
-	if err != nil {
-		log.WithError(err).Errorf("failed to create file")
-		return err
+	// Special files, such as /dev/stdout, can't be replaced
+	if info, err := os.Stat(path); err == nil && !info.Mode().IsRegular() {
+		return writeFile(ctx, src, path, 0)
 	}
 
+	var offset int64
+	if isCounter {
+		offset = int64(srcCount.Resumed())
+	}
+
+	partPath := PartPath(path)
+	validatorPath := partPath + validatorSuffix
 	start := time.Now()
-	_, err = io.Copy(file, src)
+	err := writeFile(ctx, src, partPath, offset)
 	end := time.Now()
+	// Keep the partial file if it can be resumed
+	_, statErr := os.Stat(validatorPath)
+	keep := err != nil && statErr == nil
+	if err == nil {
+		err = Verify(partPath, expect)
+		if _, ok := err.(*ErrVerify); ok {
+			log.WithError(err).Error("downloaded file is corrupt, removing it")
+		}
+	}
+	if err == nil {
+		err = os.Rename(partPath, path)
+	}
+	if keep {
+		log.WithError(err).Warn("download failed, keeping partial file to resume later")
+		return err
+	}
+	for _, rmPath := range []string{partPath, validatorPath} {
+		if rmErr := os.Remove(rmPath); rmErr != nil && !os.IsNotExist(rmErr) {
+			log.WithError(rmErr).Warnf("failed to remove '%s'", rmPath)
+		}
+	}
 	if err != nil {
-		log.WithError(err).Warnf("failed writing file")
 		return err
 	}
-	// store the error and return it after the log entry
-	err = src.Close()
 
 	if isCounter {
 		log = log.WithField("rate",
-			git_humanize.FormatByteRate(srcCount.Count(), end.Sub(start)),
+			git_humanize.FormatByteRate(srcCount.Count()-srcCount.Resumed(), end.Sub(start)),
 		)
 	}
 	log.Debugf("transferred in %s", end.Sub(start).Round(time.Millisecond))
+	return nil
+}
+
+// writeFile copies src into the file at path from offset onwards, truncating
+// anything after it, and syncs it to disk. It stops early if the context is
+// cancelled
+func writeFile(ctx context.Context, src io.Reader, path string, offset int64) error {
+	log := modlog.FromContext(ctx)
+
+	flags := os.O_WRONLY | os.O_CREATE
+	if offset == 0 {
+		flags |= os.O_TRUNC
+	}
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		log.WithError(err).Errorf("failed to create file")
+		return err
+	}
+	if offset > 0 {
+		err = file.Truncate(offset)
+		if err == nil {
+			_, err = file.Seek(offset, io.SeekStart)
+		}
+		if err != nil {
+			file.Close()
+			log.WithError(err).Errorf("failed to resume file")
+			return err
+		}
+	}
+
+	_, err = io.Copy(file, &contextReader{ctx: ctx, Reader: src})
+	if err == nil {
+		// Sync regular files only; stdout and friends may not support it
+		if info, statErr := file.Stat(); statErr == nil && info.Mode().IsRegular() {
+			err = file.Sync()
+		}
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.WithError(err).Warnf("failed writing file")
+	}
 	return err
 }
+
+// contextReader stops reading once its context is cancelled
+type contextReader struct {
+	io.Reader
+	ctx context.Context
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.Reader.Read(p)
+}
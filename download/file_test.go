@@ -0,0 +1,59 @@
+package download
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileRemovesCorrupt(t *testing.T) {
+	path := filepath.Join(tempDir(t), "mod.jar")
+	src := ioutil.NopCloser(strings.NewReader(testContent))
+	err := File(context.Background(), src, path, &Expect{Size: 1024})
+	if _, ok := err.(*ErrVerify); !ok {
+		t.Errorf("expected verification error, got %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected corrupt file to be removed, got %v", err)
+	}
+}
+
+func TestFileAtomic(t *testing.T) {
+	path := filepath.Join(tempDir(t), "mod.jar")
+	err := ioutil.WriteFile(path, []byte("old"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed or cancelled download leaves the existing file alone
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	src := ioutil.NopCloser(strings.NewReader(testContent))
+	err = File(ctx, src, path, nil)
+	if err != context.Canceled {
+		t.Errorf("expected cancelled download, got %v", err)
+	}
+	src = ioutil.NopCloser(strings.NewReader(testContent))
+	err = File(context.Background(), src, path, &Expect{Size: 1})
+	if _, ok := err.(*ErrVerify); !ok {
+		t.Errorf("expected verification error, got %v", err)
+	}
+	if data, _ := ioutil.ReadFile(path); string(data) != "old" {
+		t.Errorf("expected existing file to be kept, got %q", data)
+	}
+	if _, err := os.Stat(PartPath(path)); !os.IsNotExist(err) {
+		t.Errorf("expected partial file to be removed, got %v", err)
+	}
+
+	src = ioutil.NopCloser(strings.NewReader(testContent))
+	err = File(context.Background(), src, path, &Expect{Size: int64(len(testContent))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := ioutil.ReadFile(path); string(data) != testContent {
+		t.Errorf("expected file to be replaced, got %q", data)
+	}
+}
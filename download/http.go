@@ -2,14 +2,28 @@ package download
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/frebib/mcmod/api"
 	modlog "github.com/frebib/mcmod/log"
 )
 
 func FromURL(ctx context.Context, client *http.Client, url string) (ReadCounter, error) {
+	rd, _, err := ResumeURL(ctx, client, url, 0, "")
+	return rd, err
+}
+
+// ResumeURL requests the rest of the file at url, from offset onwards, so long
+// as the file still matches validator, as returned from an earlier request. If
+// the server can't resume the download, the whole file is returned instead, and
+// the reader reports that it wasn't resumed. The returned validator can be
+// used to resume the download later, and is empty if it can't be resumed
+func ResumeURL(ctx context.Context, client *http.Client, url string,
+	offset int64, validator string) (ReadCounter, string, error) {
+
 	log := modlog.FromContext(ctx)
 
 	if client == nil {
@@ -17,11 +31,36 @@ func FromURL(ctx context.Context, client *http.Client, url string) (ReadCounter,
 	}
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+	if offset > 0 && validator != "" {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", validator)
 	}
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	// The partial file is no longer any use, so start again
+	if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		resp.Body.Close()
+		log.Debug("server refused to resume download, starting again")
+		return ResumeURL(ctx, client, url, 0, "")
+	}
+
+	if resp.StatusCode == http.StatusPartialContent {
+		start, total, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err == nil && start != offset {
+			err = fmt.Errorf("server resumed download from %d, not %d", start, offset)
+		}
+		if err != nil {
+			resp.Body.Close()
+			return nil, "", err
+		}
+		log.Debugf("resuming download from %d bytes", offset)
+		rd := &CountingReader{Reader: resp.Body, Total: uint64(total), Offset: uint64(offset)}
+		return rd, validator, nil
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -32,10 +71,44 @@ func FromURL(ctx context.Context, client *http.Client, url string) (ReadCounter,
 	}
 
 	totalStr := resp.Header.Get("content-length")
-	total, err := strconv.ParseUint(totalStr, 10, 64)
-	if err != nil {
+	total, parseErr := strconv.ParseUint(totalStr, 10, 64)
+	if parseErr != nil {
 		total = 0
 	}
 
-	return &CountingReader{Reader: resp.Body, Total: total}, err
+	return &CountingReader{Reader: resp.Body, Total: total}, responseValidator(resp), err
+}
+
+// responseValidator returns the value to send in If-Range to resume the
+// download of the response, or an empty string if it can't be resumed
+func responseValidator(resp *http.Response) string {
+	if resp.Header.Get("Accept-Ranges") != "bytes" {
+		return ""
+	}
+	// Weak entity tags can't be used to resume downloads
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return resp.Header.Get("Last-Modified")
+}
+
+// parseContentRange returns the first byte, and the total length, of a
+// Content-Range header in the form "bytes 100-199/200"
+func parseContentRange(header string) (start, total int64, err error) {
+	_, err = fmt.Sscanf(header, "bytes %d-", &start)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid Content-Range '%s'", header)
+	}
+	idx := strings.LastIndexByte(header, '/')
+	if idx < 0 {
+		return 0, 0, fmt.Errorf("invalid Content-Range '%s'", header)
+	}
+	// The total length may be unknown
+	if totalStr := header[idx+1:]; totalStr != "*" {
+		total, err = strconv.ParseInt(totalStr, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid Content-Range '%s'", header)
+		}
+	}
+	return start, total, nil
 }
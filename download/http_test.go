@@ -0,0 +1,115 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+var testJar = bytes.Repeat([]byte("0123456789"), 1000)
+
+const testETag = `"v1"`
+
+// newFlakyServer serves testJar, but fails half-way through the first
+// request
+func newFlakyServer(t *testing.T, ranges *[]string) *httptest.Server {
+	failed := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*ranges = append(*ranges, r.Header.Get("Range"))
+		w.Header().Set("ETag", testETag)
+		if !failed {
+			failed = true
+			w.Header().Set("Accept-Ranges", "bytes")
+			w.Header().Set("Content-Length", strconv.Itoa(len(testJar)))
+			_, _ = w.Write(testJar[:len(testJar)/2])
+			return
+		}
+		http.ServeContent(w, r, "mod.jar", time.Time{}, bytes.NewReader(testJar))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFileFromURLResume(t *testing.T) {
+	var ranges []string
+	srv := newFlakyServer(t, &ranges)
+	path := filepath.Join(tempDir(t), "mod.jar")
+	expect := &Expect{Size: int64(len(testJar))}
+
+	err := FileFromURL(context.Background(), srv.URL, path, expect)
+	if err == nil {
+		t.Fatal("expected first download to fail")
+	}
+	if info, err := os.Stat(PartPath(path)); err != nil || info.Size() != int64(len(testJar)/2) {
+		t.Fatalf("expected half of the file to be kept, got %v", err)
+	}
+
+	err = FileFromURL(context.Background(), srv.URL, path, expect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := ioutil.ReadFile(path); !bytes.Equal(data, testJar) {
+		t.Errorf("resumed file doesn't match, got %d bytes", len(data))
+	}
+	if expected := "bytes=5000-"; len(ranges) != 2 || ranges[1] != expected {
+		t.Errorf("expected second request for %s, got %v", expected, ranges)
+	}
+	for _, leftover := range []string{PartPath(path), PartPath(path) + validatorSuffix} {
+		if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+			t.Errorf("expected '%s' to be removed, got %v", leftover, err)
+		}
+	}
+}
+
+func TestFileFromURLChanged(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", testETag)
+		http.ServeContent(w, r, "mod.jar", time.Time{}, bytes.NewReader(testJar))
+	}))
+	t.Cleanup(srv.Close)
+
+	// The partial file is from a different version, so can't be resumed
+	path := filepath.Join(tempDir(t), "mod.jar")
+	err := ioutil.WriteFile(PartPath(path), []byte("stale"), 0644)
+	if err == nil {
+		err = ioutil.WriteFile(PartPath(path)+validatorSuffix, []byte(`"v0"`), 0644)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = FileFromURL(context.Background(), srv.URL, path, &Expect{Size: int64(len(testJar))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := ioutil.ReadFile(path); !bytes.Equal(data, testJar) {
+		t.Errorf("downloaded file doesn't match, got %d bytes", len(data))
+	}
+}
+
+func TestParseContentRange(t *testing.T) {
+	var cases = []struct {
+		header       string
+		start, total int64
+		valid        bool
+	}{
+		{header: "bytes 100-199/200", start: 100, total: 200, valid: true},
+		{header: "bytes 0-0/*", start: 0, total: 0, valid: true},
+		{header: "bytes */200", valid: false},
+		{header: "", valid: false},
+	}
+	for _, c := range cases {
+		start, total, err := parseContentRange(c.header)
+		if (err == nil) != c.valid || start != c.start || total != c.total {
+			t.Errorf("%q: expected %d, %d (valid %t), got %d, %d (%v)",
+				c.header, c.start, c.total, c.valid, start, total, err)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package download
+
+import (
+	"context"
+	"sync"
+
+	modlog "github.com/frebib/mcmod/log"
+)
+
+// ManagerKey is the textual key used to identify
+// a Manager inside a context.Context object
+const ManagerKey = "download-manager"
+
+// DefaultConcurrency is the number of files downloaded at once by default
+const DefaultConcurrency = 4
+
+// DefaultManager downloads DefaultConcurrency files at once
+var DefaultManager = &Manager{Concurrency: DefaultConcurrency}
+
+// ManagerFromContext returns the Manager in the context, or the DefaultManager
+func ManagerFromContext(ctx context.Context) *Manager {
+	if manager, ok := ctx.Value(ManagerKey).(*Manager); ok {
+		return manager
+	}
+	return DefaultManager
+}
+
+// Job is a single file to download
+type Job struct {
+	URL  string
+	Path string
+	// Expect, if not nil, is what the downloaded file is verified against
+	Expect *Expect
+	// Key, if set, identifies the file in the Store
+	Key string
+	// Force downloads the file even if it is already present and valid
+	Force bool
+}
+
+// Manager downloads many files concurrently
+type Manager struct {
+	// Concurrency is the maximum number of files downloaded at once
+	Concurrency int
+	// Store, if not nil, is consulted before downloading any file with a key
+	Store *Store
+}
+
+// Summary is the outcome of downloading a set of files
+type Summary struct {
+	Succeeded []*Job
+	Failed    []*Job
+	// UpToDate files weren't downloaded, as they were already present and
+	// valid
+	UpToDate []*Job
+	// Skipped files weren't downloaded, as the context was cancelled first
+	Skipped []*Job
+	// Errs holds the error for each of the failed files
+	Errs []error
+}
+
+// Err returns an ErrDownloads if any files failed to download, or nil
+func (s *Summary) Err() error {
+	if len(s.Errs) < 1 {
+		return nil
+	}
+	return &ErrDownloads{Errs: s.Errs}
+}
+
+// Run downloads every job, at most Concurrency at once. A failed download
+// doesn't stop any others, but once the context is cancelled, no new downloads
+// are started. Files that are already present and match what is expected
+// aren't downloaded again, unless the job is forced
+func (m *Manager) Run(ctx context.Context, jobs []*Job) *Summary {
+	log := modlog.FromContext(ctx)
+
+	concurrency := m.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	errs := make([]error, len(jobs))
+	skipped := make([]bool, len(jobs))
+	upToDate := make([]bool, len(jobs))
+	queue := make(chan int)
+	wg := new(sync.WaitGroup)
+	for worker := 0; worker < concurrency; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range queue {
+				if ctx.Err() != nil {
+					skipped[idx] = true
+					continue
+				}
+				job := jobs[idx]
+				if !job.Force && UpToDate(job.Path, job.Expect) {
+					log.WithField("name", job.Path).Info("up to date")
+					upToDate[idx] = true
+					continue
+				}
+				if m.Store != nil {
+					errs[idx] = m.Store.FileFromURL(ctx, job.Key, job.URL, job.Path, job.Expect)
+				} else {
+					errs[idx] = FileFromURL(ctx, job.URL, job.Path, job.Expect)
+				}
+			}
+		}()
+	}
+	for idx := range jobs {
+		queue <- idx
+	}
+	close(queue)
+	wg.Wait()
+
+	summary := new(Summary)
+	for idx, job := range jobs {
+		switch {
+		case skipped[idx]:
+			summary.Skipped = append(summary.Skipped, job)
+		case upToDate[idx]:
+			summary.UpToDate = append(summary.UpToDate, job)
+		case errs[idx] != nil:
+			log.WithError(errs[idx]).WithField("name", job.Path).Error("download failed")
+			summary.Failed = append(summary.Failed, job)
+			summary.Errs = append(summary.Errs, errs[idx])
+		default:
+			summary.Succeeded = append(summary.Succeeded, job)
+		}
+	}
+	return summary
+}
@@ -0,0 +1,102 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestManagerRun(t *testing.T) {
+	var active, maxActive int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+		for max := atomic.LoadInt32(&maxActive); n > max; max = atomic.LoadInt32(&maxActive) {
+			if atomic.CompareAndSwapInt32(&maxActive, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if r.URL.Path == "/missing.jar" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, testContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	dir := tempDir(t)
+	var jobs []*Job
+	for _, name := range []string{"a.jar", "b.jar", "missing.jar", "c.jar", "d.jar"} {
+		jobs = append(jobs, &Job{
+			URL:    srv.URL + "/" + name,
+			Path:   filepath.Join(dir, name),
+			Expect: &Expect{Size: int64(len(testContent))},
+		})
+	}
+
+	manager := &Manager{Concurrency: 2}
+	summary := manager.Run(context.Background(), jobs)
+	if len(summary.Succeeded) != 4 || len(summary.Failed) != 1 || len(summary.Skipped) != 0 {
+		t.Errorf("unexpected summary: %d succeeded, %d failed, %d skipped",
+			len(summary.Succeeded), len(summary.Failed), len(summary.Skipped))
+	}
+	if errs, ok := summary.Err().(*ErrDownloads); !ok || len(errs.Errs) != 1 {
+		t.Errorf("expected one download error, got %v", summary.Err())
+	}
+	if maxActive > 2 {
+		t.Errorf("expected at most 2 concurrent downloads, got %d", maxActive)
+	}
+
+	// Files that were already downloaded are up to date, unless forced
+	summary = manager.Run(context.Background(), jobs)
+	if len(summary.UpToDate) != 4 || len(summary.Failed) != 1 {
+		t.Errorf("expected 4 files to be up to date, got %d", len(summary.UpToDate))
+	}
+	jobs[0].Force = true
+	summary = manager.Run(context.Background(), jobs)
+	if len(summary.UpToDate) != 3 || len(summary.Succeeded) != 1 || summary.Succeeded[0] != jobs[0] {
+		t.Errorf("expected forced file to be downloaded again, got %d succeeded", len(summary.Succeeded))
+	}
+
+	// Nothing is started once the context is cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	summary = manager.Run(ctx, jobs)
+	if len(summary.Skipped) != len(jobs) || summary.Err() != nil {
+		t.Errorf("expected every download to be skipped, got %d", len(summary.Skipped))
+	}
+}
+
+func TestManagerRunStore(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		fmt.Fprint(w, testContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	dir := tempDir(t)
+	manager := &Manager{Concurrency: 2, Store: &Store{Dir: filepath.Join(dir, "store")}}
+	var jobs []*Job
+	for _, name := range []string{"a.jar", "b.jar"} {
+		jobs = append(jobs, &Job{
+			URL:    srv.URL,
+			Path:   filepath.Join(dir, name),
+			Expect: &Expect{Hashes: map[string]string{"sha1": "2aae6c35c94fcfb415dbe95f408b9ce91ee846ed"}},
+			Key:    FileKey("modrinth", 1, 2),
+		})
+	}
+	summary := manager.Run(context.Background(), jobs)
+	if err := summary.Err(); err != nil || len(summary.Succeeded) != 2 {
+		t.Fatalf("expected both files to be installed: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("expected the stored file to be downloaded once, got %d requests", requests)
+	}
+}
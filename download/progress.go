@@ -0,0 +1,258 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	modlog "github.com/frebib/mcmod/log"
+	"github.com/frebib/mcmod/util"
+	git_humanize "github.com/git-lfs/git-lfs/tools/humanize"
+	"github.com/mattn/go-isatty"
+	"github.com/sirupsen/logrus"
+)
+
+// ProgressKey is the textual key used to identify
+// a Progress inside a context.Context object
+const ProgressKey = "download-progress"
+
+const (
+	// RenderInterval is how often progress bars are redrawn on a terminal
+	RenderInterval = 200 * time.Millisecond
+	// LogInterval is how often progress is logged when not on a terminal
+	LogInterval = 5 * time.Second
+
+	barWidth  = 30
+	nameWidth = 32
+	// clearLine moves the cursor up a line, and clears it
+	clearLine = "\x1b[1A\x1b[2K"
+)
+
+// ProgressFromContext returns the Progress in the context, or nil
+func ProgressFromContext(ctx context.Context) *Progress {
+	progress, _ := ctx.Value(ProgressKey).(*Progress)
+	return progress
+}
+
+// Progress displays the progress of any number of concurrent downloads. On a
+// terminal, it draws a bar for each download, and one for all of them, below
+// any log output. Otherwise, it logs the progress of each download
+// periodically
+type Progress struct {
+	out io.Writer
+	tty bool
+	log *logrus.Entry
+
+	mu    sync.Mutex
+	bars  []*Bar
+	lines int
+	// total sums every bar added, including those that have finished
+	total Bar
+	files int
+	done  int
+
+	running bool
+	stop    chan struct{}
+	stopped chan struct{}
+	logOut  io.Writer
+}
+
+// Bar is the progress of a single download
+type Bar struct {
+	Name    string
+	counter ReadCounter
+	start   time.Time
+	// count and expected are only used for the aggregate of all bars
+	count, expected, resumed uint64
+	progress                 *Progress
+}
+
+// NewProgress creates a Progress that draws on out, if it is a terminal, or
+// logs to the logger in ctx otherwise
+func NewProgress(ctx context.Context, out *os.File) *Progress {
+	fd := out.Fd()
+	return &Progress{
+		out: out,
+		tty: isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd),
+		log: modlog.FromContext(ctx),
+	}
+}
+
+// Start draws or logs progress until Stop is called. On a terminal, log
+// output is redirected through the Progress so it doesn't interrupt the bars
+func (p *Progress) Start() {
+	p.mu.Lock()
+	p.running = true
+	p.stop = make(chan struct{})
+	p.stopped = make(chan struct{})
+	p.total = Bar{Name: "total", start: time.Now()}
+	p.mu.Unlock()
+
+	interval := LogInterval
+	if p.tty {
+		interval = RenderInterval
+		// The logger holds its own lock while writing to the Progress, so
+		// must never be called while holding the lock of the Progress
+		p.logOut = p.log.Logger.Out
+		p.log.Logger.SetOutput(p)
+	}
+	go func() {
+		defer close(p.stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.tick()
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops drawing or logging progress, and removes the bars from the
+// terminal
+func (p *Progress) Stop() {
+	p.mu.Lock()
+	if !p.running {
+		p.mu.Unlock()
+		return
+	}
+	p.running = false
+	p.mu.Unlock()
+	close(p.stop)
+	<-p.stopped
+
+	if p.tty {
+		p.log.Logger.SetOutput(p.logOut)
+		p.mu.Lock()
+		p.clear()
+		p.mu.Unlock()
+	}
+}
+
+// Add starts tracking the progress of a download
+func (p *Progress) Add(name string, counter ReadCounter) *Bar {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	bar := &Bar{Name: name, counter: counter, start: time.Now(), progress: p}
+	p.bars = append(p.bars, bar)
+	p.files++
+	return bar
+}
+
+// Done stops tracking the progress of a download, once it has finished or
+// failed
+func (b *Bar) Done() {
+	p := b.progress
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for idx, bar := range p.bars {
+		if bar == b {
+			p.bars = append(p.bars[:idx], p.bars[idx+1:]...)
+			break
+		}
+	}
+	p.total.count += b.counter.Count()
+	p.total.expected += b.counter.ExpectedTotal()
+	p.total.resumed += b.counter.Resumed()
+	p.done++
+	if p.tty {
+		p.clear()
+		p.render()
+	}
+}
+
+// Write writes log output above the progress bars
+func (p *Progress) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clear()
+	n, err := p.out.Write(b)
+	p.render()
+	return n, err
+}
+
+func (p *Progress) tick() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.tty {
+		p.clear()
+		p.render()
+		return
+	}
+	for _, bar := range p.bars {
+		p.log.WithField("name", bar.Name).Info(bar.status())
+	}
+}
+
+// clear removes the bars drawn by the last render
+func (p *Progress) clear() {
+	fmt.Fprint(p.out, strings.Repeat(clearLine, p.lines))
+	p.lines = 0
+}
+
+// render draws every bar, followed by the aggregate of all bars
+func (p *Progress) render() {
+	if !p.running || len(p.bars) < 1 {
+		return
+	}
+	total := p.total
+	total.Name = fmt.Sprintf("total (%d/%d files)", p.done, p.files)
+	for _, bar := range p.bars {
+		fmt.Fprintln(p.out, bar.line())
+		total.count += bar.counter.Count()
+		total.expected += bar.counter.ExpectedTotal()
+		total.resumed += bar.counter.Resumed()
+	}
+	fmt.Fprintln(p.out, total.line())
+	p.lines = len(p.bars) + 1
+}
+
+// counts returns the bytes downloaded, expected and resumed from
+func (b *Bar) counts() (count, expected, resumed uint64) {
+	if b.counter == nil {
+		return b.count, b.expected, b.resumed
+	}
+	return b.counter.Count(), b.counter.ExpectedTotal(), b.counter.Resumed()
+}
+
+// line formats the bar as a single line to draw on a terminal
+func (b *Bar) line() string {
+	count, expected, _ := b.counts()
+	fill := barWidth
+	if expected > 0 && count < expected {
+		fill = int(count * barWidth / expected)
+	}
+	bar := strings.Repeat("=", fill) + strings.Repeat(" ", barWidth-fill)
+	if expected == 0 {
+		bar = strings.Repeat("?", barWidth)
+	}
+	return fmt.Sprintf("%-*s [%s] %s", nameWidth,
+		util.EllipsiseString(b.Name, nameWidth), bar, b.status())
+}
+
+// status describes the bytes downloaded, the transfer rate and the time
+// remaining
+func (b *Bar) status() string {
+	count, expected, resumed := b.counts()
+	elapsed := time.Since(b.start)
+	status := humanize.IBytes(count)
+	if expected > 0 {
+		status += " / " + humanize.IBytes(expected)
+	}
+	status += " " + git_humanize.FormatByteRate(count-resumed, elapsed)
+
+	rate := float64(count-resumed) / elapsed.Seconds()
+	if expected > count && rate > 0 {
+		eta := time.Duration(float64(expected-count) / rate * float64(time.Second))
+		status += " ETA " + eta.Round(time.Second).String()
+	}
+	return status
+}
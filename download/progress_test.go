@@ -0,0 +1,49 @@
+package download
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestProgressTTY(t *testing.T) {
+	out := new(bytes.Buffer)
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	p := &Progress{out: out, tty: true, log: logrus.NewEntry(logger)}
+	p.Start()
+
+	counter := &CountingReader{Reader: strings.NewReader(strings.Repeat("x", 100)), Total: 200, Offset: 100}
+	bar := p.Add("mod.jar", counter)
+	_, _ = counter.Read(make([]byte, 50))
+
+	// Log output is written above the bars, which are redrawn below it
+	logger.Info("first")
+	logger.Info("second")
+	lines := strings.Split(out.String(), "\n")
+	if len(lines) != 7 || !strings.Contains(lines[0], "first") ||
+		!strings.HasPrefix(lines[3], strings.Repeat(clearLine, 2)) ||
+		!strings.Contains(lines[3], "second") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+	if bar := lines[4]; !strings.HasPrefix(bar, "mod.jar ") ||
+		!strings.Contains(bar, "["+strings.Repeat("=", 22)+strings.Repeat(" ", 8)+"]") ||
+		!strings.Contains(bar, "150 B / 200 B") {
+		t.Errorf("unexpected bar: %s", bar)
+	}
+	if total := lines[5]; !strings.HasPrefix(total, "total (0/1 files)") {
+		t.Errorf("unexpected total: %s", total)
+	}
+
+	bar.Done()
+	p.Stop()
+	if logger.Out != ioutil.Discard {
+		t.Error("expected log output to be restored")
+	}
+	if !strings.HasSuffix(out.String(), strings.Repeat(clearLine, 2)) {
+		t.Errorf("expected bars to be cleared:\n%q", out)
+	}
+}
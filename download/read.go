@@ -1,14 +1,21 @@
 package download
 
-import "io"
+import (
+	"io"
+	"sync/atomic"
+)
 
 type ReadCounter interface {
 	io.ReadCloser
 
-	// Count returns the total sum of bytes read so far
+	// Count returns the total sum of bytes read so far, including any bytes
+	// read before the download was resumed
 	Count() uint64
 	// Total returns the expected final sum count of bytes
 	ExpectedTotal() uint64
+	// Resumed returns the number of bytes that were already downloaded before
+	// the download was resumed, or zero if it wasn't
+	Resumed() uint64
 	// IsFinished returns true when the sum of read bytes equals that of the
 	// expected final count
 	IsFinished() bool
@@ -18,12 +25,16 @@ type CountingReader struct {
 	io.Reader
 	// Total is the expected final count of the Reader
 	Total uint64
-	count uint64
+	// Offset is the count that the Reader starts from, when resuming a
+	// partial download
+	Offset uint64
+	count  uint64
 }
 
 func (c *CountingReader) Read(p []byte) (n int, err error) {
 	read, err := c.Reader.Read(p)
-	c.count += uint64(read)
+	// The count may be read concurrently, such as by a Progress
+	atomic.AddUint64(&c.count, uint64(read))
 	return read, err
 }
 
@@ -37,15 +48,19 @@ func (c *CountingReader) Close() error {
 }
 
 func (c *CountingReader) Count() uint64 {
-	return c.count
+	return c.Offset + atomic.LoadUint64(&c.count)
 }
 
 func (c *CountingReader) ExpectedTotal() uint64 {
 	return c.Total
 }
 
+func (c *CountingReader) Resumed() uint64 {
+	return c.Offset
+}
+
 func (c *CountingReader) IsFinished() bool {
-	return c.count >= c.Total
+	return c.Count() >= c.Total
 }
 
 var _ io.ReadCloser = &CountingReader{}
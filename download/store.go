@@ -0,0 +1,309 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	modlog "github.com/frebib/mcmod/log"
+)
+
+type StoreMode int
+
+const (
+	// StoreCopy copies files out of the store
+	StoreCopy StoreMode = iota
+	// StoreHardlink hardlinks files out of the store, falling back to copying
+	// them if the store is on a different filesystem
+	StoreHardlink
+	// StoreSymlink symlinks files out of the store. Pruning the store will
+	// break any links to the pruned files
+	StoreSymlink
+	// StoreDisabled downloads files directly, bypassing the store
+	StoreDisabled
+)
+
+var storeModeNames = map[StoreMode]string{
+	StoreCopy:     "copy",
+	StoreHardlink: "hardlink",
+	StoreSymlink:  "symlink",
+	StoreDisabled: "off",
+}
+
+func (m StoreMode) String() string {
+	return storeModeNames[m]
+}
+
+// ParseStoreMode parses a StoreMode from its name
+func ParseStoreMode(name string) (StoreMode, error) {
+	for mode, modeName := range storeModeNames {
+		if strings.EqualFold(name, modeName) {
+			return mode, nil
+		}
+	}
+	return StoreDisabled, fmt.Errorf("invalid store mode '%s'", name)
+}
+
+// StoreModeNames returns the names of every StoreMode, in order
+func StoreModeNames() []string {
+	names := make([]string, len(storeModeNames))
+	for mode, name := range storeModeNames {
+		names[mode] = name
+	}
+	return names
+}
+
+// DefaultStoreDir returns the directory in the user cache directory that
+// downloaded files are stored in e.g. ~/.cache/mcmod/files
+func DefaultStoreDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "mcmod", "files"), nil
+}
+
+// FileKey returns the key that identifies a file from a backend in a Store
+func FileKey(backend string, modID, fileID int) string {
+	return fmt.Sprintf("%s/%d/%d", backend, modID, fileID)
+}
+
+// Store is an on-disk store of downloaded files that is shared between every
+// mod directory, so that each file is only downloaded once. Files are keyed by
+// their project and file ID, then by their content hash, so a file that
+// changes upstream is never mistaken for the one already stored
+type Store struct {
+	// Dir is the directory that files are stored in
+	Dir  string
+	Mode StoreMode
+
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// StoreEntry is a single file in a Store
+type StoreEntry struct {
+	Key  string
+	Path string
+	Size int64
+	// Used is when the file was last downloaded or installed from the store
+	Used time.Time
+}
+
+// FileFromURL installs the file identified by key into path from the store,
+// first downloading it from url into the store if it isn't there already, or
+// doesn't match expect. Files that can't be verified, as expect gives no hash
+// or fingerprint, are never stored, as they can't be safely reused
+func (s *Store) FileFromURL(ctx context.Context, key, url, path string, expect *Expect) error {
+	if s.Mode == StoreDisabled || key == "" || contentName(expect) == "" {
+		return FileFromURL(ctx, url, path, expect)
+	}
+	log := modlog.FromContext(ctx).
+		WithField("name", path).
+		WithField("key", key)
+
+	entry := s.entryPath(key, expect)
+	// The same file must not be downloaded into the store twice at once
+	lock := s.lock(entry)
+	lock.Lock()
+	defer lock.Unlock()
+
+	_, err := os.Stat(entry)
+	if err == nil {
+		err = Verify(entry, expect)
+		if err != nil {
+			log.WithError(err).Warn("stored file is corrupt, downloading it again")
+		}
+	}
+	if err != nil {
+		err = os.MkdirAll(filepath.Dir(entry), 0755)
+		if err != nil {
+			return err
+		}
+		err = FileFromURL(ctx, url, entry, expect)
+		if err != nil {
+			return err
+		}
+	} else {
+		log.Debug("using stored file")
+		// Mark the file as used, so that it isn't pruned
+		now := time.Now()
+		err = os.Chtimes(entry, now, now)
+		if err != nil {
+			log.WithError(err).Warn("failed to update stored file time")
+		}
+	}
+	return s.install(ctx, entry, path)
+}
+
+// lock returns the mutex guarding the entry at path
+func (s *Store) lock(path string) *sync.Mutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.locks == nil {
+		s.locks = make(map[string]*sync.Mutex)
+	}
+	lock, ok := s.locks[path]
+	if !ok {
+		lock = new(sync.Mutex)
+		s.locks[path] = lock
+	}
+	return lock
+}
+
+// entryPath returns the path that the file identified by key, with the
+// contents described by expect, is stored at
+func (s *Store) entryPath(key string, expect *Expect) string {
+	return filepath.Join(s.Dir, filepath.FromSlash(key), contentName(expect))
+}
+
+// contentName names a file by the strongest hash it is expected to have, or
+// returns an empty string if it has none
+func contentName(expect *Expect) string {
+	if expect != nil {
+		for _, algo := range []string{"sha512", "sha256", "sha1", "md5"} {
+			for name, sum := range expect.Hashes {
+				if strings.EqualFold(name, algo) && sum != "" {
+					return algo + "-" + strings.ToLower(sum)
+				}
+			}
+		}
+		if expect.Fingerprint != 0 {
+			return "fingerprint-" + strconv.FormatInt(expect.Fingerprint, 10)
+		}
+	}
+	return ""
+}
+
+// install places the stored file at entry at path, replacing any file that is
+// already there
+func (s *Store) install(ctx context.Context, entry, path string) error {
+	log := modlog.FromContext(ctx).
+		WithField("name", path)
+
+	// Special files, such as /dev/stdout, can only be written to
+	if info, err := os.Stat(path); err == nil && !info.Mode().IsRegular() {
+		return copyFile(ctx, entry, path)
+	}
+
+	tmpPath := PartPath(path)
+	err := os.Remove(tmpPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	switch s.Mode {
+	case StoreHardlink:
+		err = os.Link(entry, tmpPath)
+		if err != nil {
+			log.WithError(err).Debug("failed to hardlink stored file, copying it")
+			err = copyFile(ctx, entry, tmpPath)
+		}
+	case StoreSymlink:
+		var target string
+		target, err = filepath.Abs(entry)
+		if err == nil {
+			err = os.Symlink(target, tmpPath)
+		}
+	default:
+		err = copyFile(ctx, entry, tmpPath)
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		log.WithError(err).Error("failed to install stored file")
+		return err
+	}
+	log.WithField("mode", s.Mode.String()).Info("installed file from store")
+	return nil
+}
+
+// copyFile copies the file at src into the file at dst
+func copyFile(ctx context.Context, src, dst string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return writeFile(ctx, f, dst, 0)
+}
+
+// Entries lists every file in the store, ordered by key
+func (s *Store) Entries() ([]*StoreEntry, error) {
+	var entries []*StoreEntry
+	err := filepath.Walk(s.Dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == s.Dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		// Partial downloads are hidden, and aren't entries yet
+		if !info.Mode().IsRegular() || strings.HasPrefix(info.Name(), ".") {
+			return nil
+		}
+		key, err := filepath.Rel(s.Dir, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		entries = append(entries, &StoreEntry{
+			Key:  filepath.ToSlash(key),
+			Path: path,
+			Size: info.Size(),
+			Used: info.ModTime(),
+		})
+		return nil
+	})
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+	return entries, err
+}
+
+// Usage returns the number of files in the store, and their total size
+func (s *Store) Usage() (count int, size int64, err error) {
+	entries, err := s.Entries()
+	for _, entry := range entries {
+		size += entry.Size
+	}
+	return len(entries), size, err
+}
+
+// Prune removes every file from the store that hasn't been used since before,
+// returning those that were removed
+func (s *Store) Prune(before time.Time) (removed []*StoreEntry, err error) {
+	entries, err := s.Entries()
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.Used.Before(before) {
+			continue
+		}
+		err = os.Remove(entry.Path)
+		if err != nil {
+			return removed, err
+		}
+		removed = append(removed, entry)
+		s.removeEmptyDirs(filepath.Dir(entry.Path))
+	}
+	return removed, nil
+}
+
+// removeEmptyDirs removes dir, and each of its parents inside the store, until
+// one isn't empty
+func (s *Store) removeEmptyDirs(dir string) {
+	for dir != s.Dir && strings.HasPrefix(dir, s.Dir) {
+		if os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
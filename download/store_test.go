@@ -0,0 +1,120 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStoreFileFromURL(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		fmt.Fprint(w, testContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	dir := tempDir(t)
+	expect := &Expect{Hashes: map[string]string{"sha1": "2aae6c35c94fcfb415dbe95f408b9ce91ee846ed"}}
+	key := FileKey("curseforge", 238222, 3040523)
+
+	for _, mode := range []StoreMode{StoreCopy, StoreHardlink, StoreSymlink} {
+		store := &Store{Dir: filepath.Join(dir, "store"), Mode: mode}
+		path := filepath.Join(dir, mode.String(), "mod.jar")
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = store.FileFromURL(context.Background(), key, srv.URL, path, expect)
+		if err != nil {
+			t.Fatalf("%s: %v", mode, err)
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil || string(data) != testContent {
+			t.Errorf("%s: unexpected installed file: %q, %v", mode, data, err)
+		}
+		info, err := os.Lstat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if isLink := info.Mode()&os.ModeSymlink != 0; isLink != (mode == StoreSymlink) {
+			t.Errorf("%s: unexpected file mode %s", mode, info.Mode())
+		}
+	}
+	// The file is only downloaded into the store once
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %d", requests)
+	}
+
+	store := &Store{Dir: filepath.Join(dir, "store")}
+	entries, err := store.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Key != key ||
+		filepath.Base(entries[0].Path) != "sha1-2aae6c35c94fcfb415dbe95f408b9ce91ee846ed" {
+		t.Fatalf("unexpected store entries: %#v", entries)
+	}
+
+	// Files that can't be verified are downloaded without the store
+	err = store.FileFromURL(context.Background(), key, srv.URL, filepath.Join(dir, "other.jar"), nil)
+	if err != nil || requests != 2 {
+		t.Errorf("expected unverified file to be downloaded: %v", err)
+	}
+	if entries, err := store.Entries(); err != nil || len(entries) != 1 {
+		t.Errorf("expected unverified file not to be stored: %#v, %v", entries, err)
+	}
+
+	// A corrupt stored file is downloaded again
+	err = ioutil.WriteFile(entries[0].Path, []byte("corrupt"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.FileFromURL(context.Background(), key, srv.URL, filepath.Join(dir, "mod.jar"), expect)
+	if err != nil || requests != 3 {
+		t.Errorf("expected corrupt file to be downloaded again: %v", err)
+	}
+}
+
+func TestStorePrune(t *testing.T) {
+	store := &Store{Dir: tempDir(t)}
+	old := time.Now().Add(-48 * time.Hour)
+	for _, key := range []string{"modrinth/1/2", "modrinth/1/3"} {
+		path := store.entryPath(key, &Expect{Fingerprint: 1234})
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err == nil {
+			err = ioutil.WriteFile(path, []byte(testContent), 0644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key == "modrinth/1/2" {
+			err = os.Chtimes(path, old, old)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	removed, err := store.Prune(time.Now().Add(-24 * time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || removed[0].Key != "modrinth/1/2" {
+		t.Errorf("unexpected pruned entries: %#v", removed)
+	}
+	if _, err := os.Stat(filepath.Join(store.Dir, "modrinth", "1", "2")); !os.IsNotExist(err) {
+		t.Errorf("expected empty directory to be removed, got %v", err)
+	}
+	count, size, err := store.Usage()
+	if err != nil || count != 1 || size != int64(len(testContent)) {
+		t.Errorf("unexpected usage: %d files, %d bytes, %v", count, size, err)
+	}
+}
@@ -0,0 +1,125 @@
+package download
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"hash"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/frebib/mcmod/api"
+	"github.com/frebib/mcmod/fingerprint"
+)
+
+// hashAlgos maps the names of hash algorithms, as given by the backends, onto
+// their implementations. Hashes with any other algorithm aren't checked
+var hashAlgos = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// Expect describes the file that a download should produce. Only the
+// properties that are set are checked
+type Expect struct {
+	Size        int64
+	Fingerprint int64
+	// Hashes maps hash algorithm names to hex-encoded hashes
+	Hashes map[string]string
+}
+
+// ExpectFile returns the properties of a file, as described by the backend
+func ExpectFile(file *api.File) *Expect {
+	return &Expect{
+		Size:        int64(file.FileLength),
+		Fingerprint: file.PackageFingerprint,
+		Hashes:      file.Hashes,
+	}
+}
+
+// UpToDate returns true if the file at path exists and matches expect. A file
+// is never up to date if there is nothing to check it against
+func UpToDate(path string, expect *Expect) bool {
+	if expect == nil || (expect.Size == 0 && expect.Fingerprint == 0 && len(expect.Hashes) == 0) {
+		return false
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	return Verify(path, expect) == nil
+}
+
+// Verify checks that the file at path matches every expected property,
+// returning an ErrVerify for the first that doesn't
+func Verify(path string, expect *Expect) error {
+	if expect == nil {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// Sort the algorithms, so that the first mismatch is always the same
+	algos := make([]string, 0, len(expect.Hashes))
+	for algo := range expect.Hashes {
+		if _, ok := hashAlgos[strings.ToLower(algo)]; ok {
+			algos = append(algos, algo)
+		}
+	}
+	sort.Strings(algos)
+	hashes := make([]hash.Hash, len(algos))
+	writers := make([]io.Writer, len(algos))
+	for idx, algo := range algos {
+		hashes[idx] = hashAlgos[strings.ToLower(algo)]()
+		writers[idx] = hashes[idx]
+	}
+
+	size, err := io.Copy(io.MultiWriter(writers...), f)
+	if err != nil {
+		return err
+	}
+	if expect.Size > 0 && size != expect.Size {
+		return &ErrVerify{
+			Path:     path,
+			Check:    "size",
+			Expected: strconv.FormatInt(expect.Size, 10),
+			Actual:   strconv.FormatInt(size, 10),
+		}
+	}
+	for idx, algo := range algos {
+		sum := hex.EncodeToString(hashes[idx].Sum(nil))
+		if !strings.EqualFold(sum, expect.Hashes[algo]) {
+			return &ErrVerify{Path: path, Check: algo, Expected: expect.Hashes[algo], Actual: sum}
+		}
+	}
+
+	if expect.Fingerprint != 0 {
+		_, err = f.Seek(0, io.SeekStart)
+		if err != nil {
+			return err
+		}
+		sum, err := fingerprint.Reader(f)
+		if err != nil {
+			return err
+		}
+		if int64(sum) != expect.Fingerprint {
+			return &ErrVerify{
+				Path:     path,
+				Check:    "fingerprint",
+				Expected: strconv.FormatInt(expect.Fingerprint, 10),
+				Actual:   strconv.FormatUint(uint64(sum), 10),
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package download
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/frebib/mcmod/fingerprint"
+)
+
+const testContent = "hello world"
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "mcmod-download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func TestVerify(t *testing.T) {
+	path := filepath.Join(tempDir(t), "mod.jar")
+	err := ioutil.WriteFile(path, []byte(testContent), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := int64(fingerprint.Sum([]byte(testContent)))
+
+	var cases = []struct {
+		expect *Expect
+		check  string
+	}{
+		{expect: nil},
+		{expect: &Expect{}},
+		{expect: &Expect{Size: 11, Fingerprint: valid}},
+		{expect: &Expect{Hashes: map[string]string{
+			"sha1": "2AAE6C35C94FCFB415DBE95F408B9CE91EE846ED",
+			"md5":  "5eb63bbbe01eeed093cb22bb8f5acdc3",
+			"crc":  "ignored",
+		}}},
+		{expect: &Expect{Size: 12}, check: "size"},
+		{expect: &Expect{Fingerprint: valid + 1}, check: "fingerprint"},
+		{expect: &Expect{Hashes: map[string]string{"sha1": "da39a3ee"}}, check: "sha1"},
+	}
+	for _, c := range cases {
+		err := Verify(path, c.expect)
+		if c.check == "" {
+			if err != nil {
+				t.Errorf("%#v: expected file to be valid, got %v", c.expect, err)
+			}
+			continue
+		}
+		verr, ok := err.(*ErrVerify)
+		if !ok || verr.Check != c.check {
+			t.Errorf("%#v: expected %s verification error, got %v", c.expect, c.check, err)
+		}
+	}
+}
+
+func TestUpToDate(t *testing.T) {
+	dir := tempDir(t)
+	path := filepath.Join(dir, "mod.jar")
+	err := ioutil.WriteFile(path, []byte(testContent), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := int64(fingerprint.Sum([]byte(testContent)))
+
+	var cases = []struct {
+		path     string
+		expect   *Expect
+		upToDate bool
+	}{
+		{path: path, expect: &Expect{Size: 11, Fingerprint: valid}, upToDate: true},
+		{path: path, expect: &Expect{Size: 11, Fingerprint: valid + 1}},
+		{path: path, expect: &Expect{Size: 12}},
+		{path: path, expect: &Expect{}},
+		{path: path, expect: nil},
+		{path: filepath.Join(dir, "missing.jar"), expect: &Expect{Size: 11}},
+		{path: dir, expect: &Expect{Size: 11}},
+	}
+	for _, c := range cases {
+		if upToDate := UpToDate(c.path, c.expect); upToDate != c.upToDate {
+			t.Errorf("%s %#v: expected up to date %t, got %t", c.path, c.expect, c.upToDate, upToDate)
+		}
+	}
+}
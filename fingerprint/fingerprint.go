@@ -0,0 +1,146 @@
+// Package fingerprint implements the file fingerprint used by CurseForge to
+// identify files. A fingerprint is the 32-bit MurmurHash2 of the file, with a
+// seed of 1, after every whitespace byte has been removed from it
+package fingerprint
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"os"
+)
+
+const (
+	seed = 1
+	m    = 0x5bd1e995
+	r    = 24
+)
+
+// isWhitespace reports whether a byte is stripped before hashing
+func isWhitespace(b byte) bool {
+	return b == '\t' || b == '\n' || b == '\r' || b == ' '
+}
+
+// Sum returns the fingerprint of data
+func Sum(data []byte) uint32 {
+	stripped := make([]byte, 0, len(data))
+	for _, b := range data {
+		if !isWhitespace(b) {
+			stripped = append(stripped, b)
+		}
+	}
+	return murmur2(stripped, seed)
+}
+
+// File returns the fingerprint of the file at path. The hash depends on the
+// length of the stripped file, so the file is read twice rather than held in
+// memory
+func File(path string) (uint32, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return Reader(f)
+}
+
+// Reader returns the fingerprint of everything in r, reading it twice
+func Reader(r io.ReadSeeker) (uint32, error) {
+	length, err := strippedLength(r)
+	if err != nil {
+		return 0, err
+	}
+	_, err = r.Seek(0, io.SeekStart)
+	if err != nil {
+		return 0, err
+	}
+
+	h := newHash(length)
+	buf := bufio.NewReader(r)
+	for {
+		b, err := buf.ReadByte()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return 0, err
+		}
+		if !isWhitespace(b) {
+			h.writeByte(b)
+		}
+	}
+	return h.sum(), nil
+}
+
+func strippedLength(r io.Reader) (uint32, error) {
+	var length uint32
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		for _, b := range buf[:n] {
+			if !isWhitespace(b) {
+				length++
+			}
+		}
+		if err == io.EOF {
+			return length, nil
+		} else if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// hash is an incremental MurmurHash2, which must know the length of its input
+// in advance
+type hash struct {
+	h     uint32
+	block [4]byte
+	n     int
+}
+
+func newHash(length uint32) *hash {
+	return &hash{h: seed ^ length}
+}
+
+func (h *hash) writeByte(b byte) {
+	h.block[h.n] = b
+	h.n++
+	if h.n == len(h.block) {
+		h.mix(binary.LittleEndian.Uint32(h.block[:]))
+		h.n = 0
+	}
+}
+
+func (h *hash) mix(k uint32) {
+	k *= m
+	k ^= k >> r
+	k *= m
+	h.h *= m
+	h.h ^= k
+}
+
+func (h *hash) sum() uint32 {
+	sum := h.h
+	switch h.n {
+	case 3:
+		sum ^= uint32(h.block[2]) << 16
+		fallthrough
+	case 2:
+		sum ^= uint32(h.block[1]) << 8
+		fallthrough
+	case 1:
+		sum ^= uint32(h.block[0])
+		sum *= m
+	}
+	sum ^= sum >> 13
+	sum *= m
+	sum ^= sum >> 15
+	return sum
+}
+
+func murmur2(data []byte, seed uint32) uint32 {
+	h := &hash{h: seed ^ uint32(len(data))}
+	for _, b := range data {
+		h.writeByte(b)
+	}
+	return h.sum()
+}
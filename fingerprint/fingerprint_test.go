@@ -0,0 +1,34 @@
+package fingerprint
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSum(t *testing.T) {
+	var cases = []struct {
+		data        string
+		fingerprint uint32
+	}{
+		{data: "", fingerprint: 1540447798},
+		{data: "a", fingerprint: 626045324},
+		{data: "abcd", fingerprint: 3376380438},
+		{data: "abcdefg", fingerprint: 184182053},
+		{data: "hello world", fingerprint: 2824650221},
+		{data: "helloworld", fingerprint: 2824650221},
+		{data: "hello\tworld\r\n", fingerprint: 2824650221},
+		{data: "The quick brown fox jumps over the lazy dog", fingerprint: 3751777527},
+	}
+	for _, c := range cases {
+		if sum := Sum([]byte(c.data)); sum != c.fingerprint {
+			t.Errorf("%q: expected fingerprint %d, got %d", c.data, c.fingerprint, sum)
+		}
+		sum, err := Reader(bytes.NewReader([]byte(c.data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sum != c.fingerprint {
+			t.Errorf("%q: expected streamed fingerprint %d, got %d", c.data, c.fingerprint, sum)
+		}
+	}
+}
@@ -2,11 +2,17 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"os"
+	"strings"
 
 	"github.com/frebib/mcmod/api"
 	"github.com/frebib/mcmod/cmd"
+	"github.com/frebib/mcmod/config"
+	"github.com/frebib/mcmod/download"
 	modlog "github.com/frebib/mcmod/log"
+	mc "github.com/frebib/mcmod/minecraft"
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
 	"github.com/x-cray/logrus-prefixed-formatter"
@@ -19,6 +25,72 @@ var (
 		Aliases: []string{"l"},
 		Value:   logrus.InfoLevel.String(),
 	}
+	configFlag = cli.PathFlag{
+		Name:        "config",
+		Usage:       "path to the config file",
+		DefaultText: "$XDG_CONFIG_HOME/mcmod/" + config.FileName,
+		Aliases:     []string{"c"},
+		EnvVars:     []string{"MCMOD_CONFIG"},
+	}
+	apiKeyFlag = cli.StringFlag{
+		Name:    "curseforge-api-key",
+		Usage:   "key for the CurseForge API",
+		EnvVars: []string{"CURSEFORGE_API_KEY"},
+	}
+	noCacheFlag = cli.BoolFlag{
+		Name:    "no-cache",
+		Usage:   "do not read or write cached API responses",
+		EnvVars: []string{"NO_CACHE"},
+	}
+	refreshFlag = cli.BoolFlag{
+		Name:  "refresh",
+		Usage: "revalidate all cached API responses",
+	}
+	cacheTTLFlag = cli.DurationFlag{
+		Name:    "cache-ttl",
+		Usage:   "how long cached API responses are used before revalidating",
+		Value:   api.DefaultCacheTTL,
+		EnvVars: []string{"CACHE_TTL"},
+	}
+	retriesFlag = cli.IntFlag{
+		Name:    "retries",
+		Usage:   "number of times a failed API request is retried",
+		Value:   api.DefaultRetries,
+		EnvVars: []string{"API_RETRIES"},
+	}
+	rateLimitFlag = cli.Float64Flag{
+		Name:    "rate-limit",
+		Usage:   "maximum API requests per second, or 0 for no limit",
+		Value:   api.DefaultRateLimit,
+		EnvVars: []string{"API_RATE_LIMIT"},
+	}
+	jobsFlag = cli.IntFlag{
+		Name:    "jobs",
+		Usage:   "number of files downloaded at once",
+		Aliases: []string{"j"},
+		Value:   download.DefaultConcurrency,
+		EnvVars: []string{"DOWNLOAD_JOBS"},
+	}
+	storeFlag = cli.StringFlag{
+		Name: "store",
+		Usage: fmt.Sprintf("how files are installed from the download store, of [%s]",
+			strings.Join(download.StoreModeNames(), ", ")),
+		Value:   download.StoreCopy.String(),
+		EnvVars: []string{"DOWNLOAD_STORE"},
+	}
+	versionManifestFlag = cli.StringFlag{
+		Name:    "version-manifest",
+		Usage:   "path or URL of the game version manifest, instead of fetching it from Mojang",
+		EnvVars: []string{"MINECRAFT_VERSION_MANIFEST"},
+	}
+	backendFlag = cli.StringFlag{
+		Name: "backend",
+		Usage: fmt.Sprintf("mod source, of [%s]",
+			strings.Join(api.BackendNames(), ", ")),
+		Aliases: []string{"b"},
+		Value:   api.DefaultBackend,
+		EnvVars: []string{"MOD_BACKEND"},
+	}
 )
 
 func init() {
@@ -42,10 +114,28 @@ func main() {
 
 		Commands: []*cli.Command{
 			cmd.Get,
+			cmd.Install,
+			cmd.Update,
+			cmd.Outdated,
+			cmd.Remove,
+			cmd.Import,
+			cmd.Verify,
 			cmd.Search,
+			cmd.Cache,
 		},
 		Flags: []cli.Flag{
 			&lvlFlag,
+			&configFlag,
+			&backendFlag,
+			&apiKeyFlag,
+			&noCacheFlag,
+			&refreshFlag,
+			&cacheTTLFlag,
+			&retriesFlag,
+			&rateLimitFlag,
+			&jobsFlag,
+			&storeFlag,
+			&versionManifestFlag,
 		},
 		Before: func(c *cli.Context) error {
 			log := modlog.FromContext(c.Context)
@@ -55,7 +145,58 @@ func main() {
 			}
 			log.Logger.SetLevel(lvl)
 
-			c.Context = context.WithValue(ctx, api.ClientKey, api.DefaultClient)
+			confPath := c.Path(configFlag.Name)
+			if confPath == "" {
+				confPath, err = config.DefaultPath()
+				if err != nil {
+					return err
+				}
+			}
+			conf, err := config.Load(confPath)
+			if err != nil {
+				return err
+			}
+			log.WithField("path", confPath).Trace("loaded config")
+
+			// Flags and environment variables override the config file
+			backendName := conf.Backend
+			if backendName == "" || c.IsSet(backendFlag.Name) {
+				backendName = c.String(backendFlag.Name)
+			}
+			apiKey := conf.CurseForge.ApiKey
+			if c.IsSet(apiKeyFlag.Name) {
+				apiKey = c.String(apiKeyFlag.Name)
+			}
+
+			cache, err := newCache(c, conf)
+			if err != nil {
+				return err
+			}
+			// Cached responses shouldn't count towards the rate limit, so
+			// the retry policy sits beneath the cache
+			retry := newRetryPolicy(c, conf)
+			backend, err := api.NewBackend(backendName, &api.BackendConfig{
+				HttpClient: &http.Client{
+					Transport: cache.Transport(retry.Transport(nil)),
+				},
+				CurseForgeApiKey: apiKey,
+			})
+			if err != nil {
+				return err
+			}
+			c.Context = context.WithValue(ctx, config.ContextKey, conf)
+			c.Context = context.WithValue(c.Context, api.CacheKey, cache)
+			c.Context = context.WithValue(c.Context, api.BackendKey, backend)
+			manager, err := newDownloadManager(c, conf)
+			if err != nil {
+				return err
+			}
+			c.Context = context.WithValue(c.Context, download.ManagerKey, manager)
+			manifestLoader, err := newManifestLoader(c, conf, cache, retry)
+			if err != nil {
+				return err
+			}
+			c.Context = context.WithValue(c.Context, mc.ManifestKey, manifestLoader)
 
 			return nil
 		},
@@ -66,3 +207,99 @@ func main() {
 		log.WithError(err).Fatal()
 	}
 }
+
+// newCache configures the API response cache from
+// the flags, falling back to the config file
+func newCache(c *cli.Context, conf *config.Config) (cache *api.Cache, err error) {
+	cache = &api.Cache{
+		Dir: conf.Cache.Dir,
+		TTL: conf.Cache.TTL.Duration,
+	}
+	if cache.Dir == "" {
+		cache.Dir, err = api.DefaultCacheDir()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cache.TTL == 0 || c.IsSet(cacheTTLFlag.Name) {
+		cache.TTL = c.Duration(cacheTTLFlag.Name)
+	}
+	if c.Bool(noCacheFlag.Name) {
+		cache.Mode = api.CacheDisabled
+	} else if c.Bool(refreshFlag.Name) {
+		cache.Mode = api.CacheRefresh
+	}
+	return cache, nil
+}
+
+// newRetryPolicy configures the API retry policy and rate
+// limit from the flags, falling back to the config file
+func newRetryPolicy(c *cli.Context, conf *config.Config) *api.RetryPolicy {
+	retries := c.Int(retriesFlag.Name)
+	if conf.HTTP.Retries != nil && !c.IsSet(retriesFlag.Name) {
+		retries = *conf.HTTP.Retries
+	}
+	limit := c.Float64(rateLimitFlag.Name)
+	if conf.HTTP.RateLimit != nil && !c.IsSet(rateLimitFlag.Name) {
+		limit = *conf.HTTP.RateLimit
+	}
+	burst := api.DefaultRateBurst
+	if conf.HTTP.RateBurst != nil {
+		burst = *conf.HTTP.RateBurst
+	}
+	return api.NewRetryPolicy(retries, limit, burst)
+}
+
+// newDownloadManager configures how files are downloaded
+// from the flags, falling back to the config file
+func newDownloadManager(c *cli.Context, conf *config.Config) (*download.Manager, error) {
+	jobs := c.Int(jobsFlag.Name)
+	if conf.Download.Jobs > 0 && !c.IsSet(jobsFlag.Name) {
+		jobs = conf.Download.Jobs
+	}
+
+	modeName := c.String(storeFlag.Name)
+	if conf.Download.Store != "" && !c.IsSet(storeFlag.Name) {
+		modeName = conf.Download.Store
+	}
+	mode, err := download.ParseStoreMode(modeName)
+	if err != nil {
+		return nil, err
+	}
+	store := &download.Store{Dir: conf.Download.StoreDir, Mode: mode}
+	if store.Dir == "" {
+		store.Dir, err = download.DefaultStoreDir()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &download.Manager{Concurrency: jobs, Store: store}, nil
+}
+
+// newManifestLoader configures where the game version manifest is loaded from
+// when it is needed, from the flags, falling back to the config file
+func newManifestLoader(c *cli.Context, conf *config.Config, cache *api.Cache,
+	retry *api.RetryPolicy) (*mc.ManifestLoader, error) {
+
+	loader := &mc.ManifestLoader{
+		Source: conf.Minecraft.Manifest,
+		TTL:    mc.DefaultManifestTTL,
+		Client: &http.Client{Transport: retry.Transport(nil)},
+	}
+	if c.IsSet(versionManifestFlag.Name) {
+		loader.Source = c.String(versionManifestFlag.Name)
+	}
+	// The manifest obeys the same cache settings as API responses
+	switch cache.Mode {
+	case api.CacheRefresh:
+		loader.TTL = 0
+		fallthrough
+	case api.CacheEnabled:
+		path, err := mc.DefaultManifestCachePath()
+		if err != nil {
+			return nil, err
+		}
+		loader.CachePath = path
+	}
+	return loader, nil
+}
@@ -0,0 +1,16 @@
+package manifest
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrNoModRef = errors.New("mod has neither a slug nor an id")
+
+type ErrInvalidSide struct {
+	Side string
+}
+
+func (e *ErrInvalidSide) Error() string {
+	return fmt.Sprintf("invalid side '%s', expected one of [both, client, server]", e.Side)
+}
@@ -0,0 +1,212 @@
+package manifest
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/frebib/mcmod/util"
+)
+
+// LockFileExt is the extension of a lockfile, which is stored alongside the
+// manifest it was resolved from
+const LockFileExt = ".lock"
+
+const lockHeader = "# This file is generated by mcmod. Do not edit it by hand.\n\n"
+
+// Lock records the exact files installed from a manifest, including every
+// dependency, so that the same files can be installed again later
+type Lock struct {
+	// Backend is the name of the backend that the ids are from
+	Backend     string `toml:"backend"`
+	GameVersion string `toml:"game-version,omitempty"`
+	Loader      string `toml:"loader,omitempty"`
+	Side        Side   `toml:"side,omitempty"`
+	// Optional is true if optional dependencies were installed too
+	Optional bool        `toml:"with-optional,omitempty"`
+	Mods     []LockedMod `toml:"mod"`
+}
+
+// LockedMod is a single file in a lockfile, in install order
+type LockedMod struct {
+	ID   int    `toml:"id"`
+	Slug string `toml:"slug,omitempty"`
+	// Root is true for mods listed in the manifest, rather than installed as
+	// a dependency
+	Root bool `toml:"root,omitempty"`
+	// Release is the least stable release type the file was chosen from
+	Release string `toml:"release,omitempty"`
+
+	// The file fields describe the exact file installed. FileRelease is the
+	// release type of the file itself
+	FileID      int               `toml:"file-id"`
+	FileName    string            `toml:"file-name"`
+	FileDate    time.Time         `toml:"file-date,omitempty"`
+	FileRelease string            `toml:"file-release,omitempty"`
+	DownloadURL string            `toml:"download-url"`
+	Size        int               `toml:"size,omitzero"`
+	Fingerprint int64             `toml:"fingerprint,omitzero"`
+	Hashes      map[string]string `toml:"hashes,omitempty"`
+
+	// Dependencies are the ids of the locked mods that this mod depends on
+	Dependencies []int `toml:"dependencies,omitempty"`
+}
+
+// Name returns the slug of the mod, or its id if the slug is unknown
+func (m *LockedMod) Name() string {
+	if m.Slug != "" {
+		return m.Slug
+	}
+	return (&Mod{ID: m.ID}).Ref()
+}
+
+// LockPath returns the path of the lockfile for the manifest at path
+func LockPath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + LockFileExt
+}
+
+// LoadLock reads a lockfile. If the file doesn't exist, the error satisfies
+// os.IsNotExist
+func LoadLock(path string) (*Lock, error) {
+	lock := new(Lock)
+	_, err := toml.DecodeFile(path, lock)
+	if err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+// Save writes a lockfile to a file, replacing any existing file
+func (l *Lock) Save(path string) error {
+	buf := bytes.NewBufferString(lockHeader)
+	err := toml.NewEncoder(buf).Encode(l)
+	if err != nil {
+		return err
+	}
+	return util.WriteFileAtomic(path, buf.Bytes(), 0644)
+}
+
+// Find returns the locked mod with the given id, or nil
+func (l *Lock) Find(id int) *LockedMod {
+	for idx := range l.Mods {
+		if l.Mods[idx].ID == id {
+			return &l.Mods[idx]
+		}
+	}
+	return nil
+}
+
+// Dependents returns the ids of the locked mods that depend on the mod with
+// the given id
+func (l *Lock) Dependents(id int) []int {
+	var dependents []int
+	for _, mod := range l.Mods {
+		for _, dep := range mod.Dependencies {
+			if dep == id {
+				dependents = append(dependents, mod.ID)
+				break
+			}
+		}
+	}
+	return dependents
+}
+
+// Orphans returns the ids of the mods that were installed only as
+// dependencies of the mod with the given id, directly or transitively, and
+// that nothing else depends on. Mods listed in the manifest are never orphans
+func (l *Lock) Orphans(id int) []int {
+	removed := map[int]bool{id: true}
+	var orphans []int
+	for changed := true; changed; {
+		changed = false
+		for _, mod := range l.Mods {
+			if mod.Root || removed[mod.ID] {
+				continue
+			}
+			dependents := l.Dependents(mod.ID)
+			orphaned := len(dependents) > 0
+			for _, dependent := range dependents {
+				orphaned = orphaned && removed[dependent]
+			}
+			if orphaned {
+				removed[mod.ID] = true
+				orphans = append(orphans, mod.ID)
+				changed = true
+			}
+		}
+	}
+	return orphans
+}
+
+// Remove removes the mods with the given ids, and any dependencies on them
+func (l *Lock) Remove(ids ...int) {
+	removed := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		removed[id] = true
+	}
+	mods := l.Mods[:0]
+	for _, mod := range l.Mods {
+		if removed[mod.ID] {
+			continue
+		}
+		deps := mod.Dependencies[:0]
+		for _, dep := range mod.Dependencies {
+			if !removed[dep] {
+				deps = append(deps, dep)
+			}
+		}
+		if len(deps) < 1 {
+			deps = nil
+		}
+		mod.Dependencies = deps
+		mods = append(mods, mod)
+	}
+	l.Mods = mods
+}
+
+// Matches reports whether the lockfile was resolved from the manifest, as it
+// is now, for the given side and with optional dependencies or not. If not, it
+// needs to be resolved again
+func (l *Lock) Matches(mf *Manifest, side Side, optional bool) bool {
+	if l.GameVersion != mf.GameVersion || !strings.EqualFold(l.Loader, mf.Loader) ||
+		l.Side != side || l.Optional != optional {
+		return false
+	}
+
+	roots := 0
+	for _, mod := range l.Mods {
+		if mod.Root {
+			roots++
+		}
+	}
+	for _, mod := range mf.Mods {
+		if !mod.Side.Includes(side) {
+			continue
+		}
+		roots--
+		locked := l.findRoot(&mod)
+		if locked == nil || locked.Release != mf.ReleaseOf(&mod) {
+			return false
+		}
+		if mod.FileID != 0 && locked.FileID != mod.FileID {
+			return false
+		}
+	}
+	return roots == 0
+}
+
+// findRoot returns the locked root mod for a mod in a manifest, or nil
+func (l *Lock) findRoot(mod *Mod) *LockedMod {
+	for idx, locked := range l.Mods {
+		if !locked.Root {
+			continue
+		}
+		if (mod.ID != 0 && mod.ID == locked.ID) ||
+			(mod.Slug != "" && strings.EqualFold(mod.Slug, locked.Slug)) {
+			return &l.Mods[idx]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package manifest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testLock() *Lock {
+	return &Lock{
+		Backend:     "curseforge",
+		GameVersion: "1.16.5",
+		Loader:      "forge",
+		Side:        SideBoth,
+		Mods: []LockedMod{
+			{
+				ID: 1, Slug: "lib", Release: "release",
+				FileID: 10, FileName: "lib.jar", DownloadURL: "https://cdn/lib.jar",
+				FileDate:    time.Date(2021, 1, 3, 0, 0, 0, 0, time.UTC),
+				FileRelease: "beta",
+				Size:        1024, Fingerprint: 1234,
+				Hashes: map[string]string{"sha1": "da39a3ee"},
+			},
+			{
+				ID: 238222, Slug: "jei", Root: true, Release: "release",
+				FileID: 3040523, FileName: "jei.jar", DownloadURL: "https://cdn/jei.jar",
+				FileDate:     time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC),
+				Dependencies: []int{1},
+			},
+		},
+	}
+}
+
+func TestLockLoadSave(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mcmod-lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "mcmod.lock")
+	if _, err := LoadLock(path); !os.IsNotExist(err) {
+		t.Fatalf("expected missing lockfile error, got %v", err)
+	}
+
+	lock := testLock()
+	lock.Optional = true
+	err = lock.Save(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := LoadLock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(saved, lock) {
+		t.Errorf("lockfile did not round-trip:\nexpected: %#v\ngot:      %#v", lock, saved)
+	}
+	if deps := saved.Dependents(1); !reflect.DeepEqual(deps, []int{238222}) {
+		t.Errorf("expected dependents [238222], got %v", deps)
+	}
+}
+
+func TestLockPath(t *testing.T) {
+	if path := LockPath(filepath.Join("pack", FileName)); path != filepath.Join("pack", "mcmod.lock") {
+		t.Errorf("unexpected lockfile path %s", path)
+	}
+}
+
+func TestLockMatches(t *testing.T) {
+	var cases = []struct {
+		name     string
+		mf       Manifest
+		side     Side
+		optional bool
+		matches  bool
+	}{
+		{
+			name:    "by slug",
+			mf:      Manifest{GameVersion: "1.16.5", Loader: "Forge", Mods: []Mod{{Slug: "JEI"}}},
+			matches: true,
+		},
+		{
+			name:    "by id",
+			mf:      Manifest{GameVersion: "1.16.5", Loader: "forge", Mods: []Mod{{ID: 238222, FileID: 3040523}}},
+			matches: true,
+		},
+		{
+			name:    "other side only",
+			mf:      Manifest{GameVersion: "1.16.5", Loader: "forge", Mods: []Mod{{Slug: "jei"}, {Slug: "sodium", Side: SideServer}}},
+			side:    SideClient,
+			matches: true,
+		},
+		{
+			name:     "optional dependencies",
+			mf:       Manifest{GameVersion: "1.16.5", Loader: "forge", Mods: []Mod{{Slug: "jei"}}},
+			optional: true,
+		},
+		{
+			name: "mod added",
+			mf:   Manifest{GameVersion: "1.16.5", Loader: "forge", Mods: []Mod{{Slug: "jei"}, {Slug: "sodium"}}},
+		},
+		{
+			name: "mod removed",
+			mf:   Manifest{GameVersion: "1.16.5", Loader: "forge"},
+		},
+		{
+			name: "version changed",
+			mf:   Manifest{GameVersion: "1.17.1", Loader: "forge", Mods: []Mod{{Slug: "jei"}}},
+		},
+		{
+			name: "release changed",
+			mf:   Manifest{GameVersion: "1.16.5", Loader: "forge", Mods: []Mod{{Slug: "jei", Release: "beta"}}},
+		},
+		{
+			name: "file pinned",
+			mf:   Manifest{GameVersion: "1.16.5", Loader: "forge", Mods: []Mod{{Slug: "jei", FileID: 1}}},
+		},
+	}
+	for _, c := range cases {
+		lock := testLock()
+		if c.side != "" {
+			lock.Side = c.side
+		}
+		if lock.Matches(&c.mf, lock.Side, c.optional) != c.matches {
+			t.Errorf("%s: expected matches %t", c.name, c.matches)
+		}
+	}
+}
+
+func TestLockOrphans(t *testing.T) {
+	// 1 and 2 are in the manifest. 1 depends on 3 and 4, 2 depends on 4,
+	// and 3 depends on 5
+	lock := &Lock{Mods: []LockedMod{
+		{ID: 5},
+		{ID: 3, Dependencies: []int{5}},
+		{ID: 4},
+		{ID: 1, Root: true, Dependencies: []int{3, 4}},
+		{ID: 2, Root: true, Dependencies: []int{4}},
+	}}
+	if orphans := lock.Orphans(1); !reflect.DeepEqual(orphans, []int{3, 5}) {
+		t.Errorf("expected orphans [3 5], got %v", orphans)
+	}
+	if orphans := lock.Orphans(2); len(orphans) > 0 {
+		t.Errorf("expected no orphans, got %v", orphans)
+	}
+
+	lock.Remove(1, 4)
+	var ids []int
+	for _, mod := range lock.Mods {
+		ids = append(ids, mod.ID)
+	}
+	if !reflect.DeepEqual(ids, []int{5, 3, 2}) {
+		t.Errorf("expected mods [5 3 2] to remain, got %v", ids)
+	}
+	if deps := lock.Find(2).Dependencies; deps != nil {
+		t.Errorf("expected dependency on removed mod to be dropped, got %v", deps)
+	}
+}
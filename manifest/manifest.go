@@ -0,0 +1,175 @@
+// Package manifest reads and writes mod list manifests. A manifest declares
+// the mods to install, and the game version and mod loader to install them
+// for, such as
+//
+//	game-version = "1.16.5"
+//	loader = "forge"
+//
+//	[[mod]]
+//	slug = "jei"
+//
+//	[[mod]]
+//	id = 238222
+//	release = "beta"
+//	file-id = 3040523
+//	side = "client"
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/frebib/mcmod/util"
+)
+
+// FileName is the default name of a manifest file
+const FileName = "mcmod.toml"
+
+// DefaultRelease is the release type installed when the manifest doesn't
+// specify one
+const DefaultRelease = "release"
+
+type Manifest struct {
+	GameVersion string `toml:"game-version,omitempty"`
+	Loader      string `toml:"loader,omitempty"`
+	// Release is the least stable release type to install, unless
+	// overridden for an individual mod
+	Release string `toml:"release,omitempty"`
+	Mods    []Mod  `toml:"mod"`
+}
+
+// Mod is a single mod in a manifest, identified by either slug or id
+type Mod struct {
+	Slug string `toml:"slug,omitempty"`
+	ID   int    `toml:"id,omitzero"`
+	// Release overrides the release type of the manifest for this mod
+	Release string `toml:"release,omitempty"`
+	// FileID pins the mod to a specific file
+	FileID int  `toml:"file-id,omitzero"`
+	Side   Side `toml:"side,omitempty"`
+}
+
+// Ref returns the id of the mod, or the slug if the id is unknown, in the form
+// accepted by api.Backend.Lookup
+func (m *Mod) Ref() string {
+	if m.ID != 0 {
+		return strconv.Itoa(m.ID)
+	}
+	return m.Slug
+}
+
+// Side is where a mod is needed, on the client, the server or both
+type Side string
+
+const (
+	SideBoth   Side = "both"
+	SideClient Side = "client"
+	SideServer Side = "server"
+)
+
+func ParseSide(s string) (Side, error) {
+	switch side := Side(strings.ToLower(s)); side {
+	case "", SideBoth:
+		return SideBoth, nil
+	case SideClient, SideServer:
+		return side, nil
+	}
+	return "", &ErrInvalidSide{s}
+}
+
+// Includes reports whether a mod for this side is needed on the other side.
+// Mods with no side are needed on both sides
+func (s Side) Includes(other Side) bool {
+	if s == "" || s == SideBoth || other == "" || other == SideBoth {
+		return true
+	}
+	return s == other
+}
+
+// ReleaseOf returns the least stable release type to install for a mod in the
+// manifest, or for its dependencies if mod is nil
+func (m *Manifest) ReleaseOf(mod *Mod) string {
+	if mod != nil && mod.Release != "" {
+		return mod.Release
+	}
+	if m.Release != "" {
+		return m.Release
+	}
+	return DefaultRelease
+}
+
+// Load reads a manifest from a file, and checks it for errors
+func Load(path string) (*Manifest, error) {
+	manifest := new(Manifest)
+	_, err := toml.DecodeFile(path, manifest)
+	if err != nil {
+		return nil, err
+	}
+	return manifest, manifest.Validate()
+}
+
+// Save writes a manifest to a file, replacing any existing file
+func (m *Manifest) Save(path string) error {
+	buf := new(bytes.Buffer)
+	err := toml.NewEncoder(buf).Encode(m)
+	if err != nil {
+		return err
+	}
+	return util.WriteFileAtomic(path, buf.Bytes(), 0644)
+}
+
+// Validate checks that every mod can be identified, and that no mod is
+// listed more than once
+func (m *Manifest) Validate() error {
+	seen := make(map[string]bool, len(m.Mods))
+	for idx, mod := range m.Mods {
+		if mod.Slug == "" && mod.ID == 0 {
+			return fmt.Errorf("mod %d: %w", idx+1, ErrNoModRef)
+		}
+		if _, err := ParseSide(string(mod.Side)); err != nil {
+			return fmt.Errorf("mod '%s': %w", mod.Ref(), err)
+		}
+		for _, ref := range []string{mod.Slug, strconv.Itoa(mod.ID)} {
+			if ref == "" || ref == "0" {
+				continue
+			}
+			if seen[ref] {
+				return fmt.Errorf("mod '%s' is listed more than once", ref)
+			}
+			seen[ref] = true
+		}
+	}
+	return nil
+}
+
+// Remove removes the mod with the given slug or id, reporting whether it was
+// in the manifest
+func (m *Manifest) Remove(ref string) bool {
+	idx := m.index(ref)
+	if idx < 0 {
+		return false
+	}
+	m.Mods = append(m.Mods[:idx], m.Mods[idx+1:]...)
+	return true
+}
+
+// Find returns the mod in the manifest with the given slug or id, or nil
+func (m *Manifest) Find(ref string) *Mod {
+	if idx := m.index(ref); idx >= 0 {
+		return &m.Mods[idx]
+	}
+	return nil
+}
+
+// index returns the index of the mod with the given slug or id, or -1
+func (m *Manifest) index(ref string) int {
+	for idx, mod := range m.Mods {
+		if strings.EqualFold(mod.Slug, ref) || (mod.ID != 0 && strconv.Itoa(mod.ID) == ref) {
+			return idx
+		}
+	}
+	return -1
+}
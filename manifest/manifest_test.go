@@ -0,0 +1,112 @@
+package manifest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testManifest = `
+game-version = "1.16.5"
+loader = "forge"
+
+[[mod]]
+slug = "jei"
+
+[[mod]]
+id = 238222
+release = "beta"
+file-id = 3040523
+side = "client"
+`
+
+func writeTemp(t *testing.T, content string) string {
+	dir, err := ioutil.TempDir("", "mcmod-manifest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, FileName)
+	err = ioutil.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadSave(t *testing.T) {
+	path := writeTemp(t, testManifest)
+	mf, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &Manifest{
+		GameVersion: "1.16.5",
+		Loader:      "forge",
+		Mods: []Mod{
+			{Slug: "jei"},
+			{ID: 238222, Release: "beta", FileID: 3040523, Side: SideClient},
+		},
+	}
+	if !reflect.DeepEqual(mf, expected) {
+		t.Fatalf("unexpected manifest:\nexpected: %#v\ngot:      %#v", expected, mf)
+	}
+	if mod := mf.Find("238222"); mod != &mf.Mods[1] {
+		t.Errorf("expected to find mod by id, got %#v", mod)
+	}
+	if !mf.Remove("JEI") || mf.Remove("jei") || len(mf.Mods) != 1 {
+		t.Errorf("expected to remove mod by slug once, got %#v", mf.Mods)
+	}
+	mf.Mods = expected.Mods
+
+	err = mf.Save(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(saved, expected) {
+		t.Errorf("manifest did not round-trip:\nexpected: %#v\ngot:      %#v", expected, saved)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	var cases = []struct {
+		mods  []Mod
+		valid bool
+	}{
+		{mods: []Mod{{Slug: "jei"}, {ID: 1}}, valid: true},
+		{mods: []Mod{{Release: "beta"}}, valid: false},
+		{mods: []Mod{{Slug: "jei", Side: "sky"}}, valid: false},
+		{mods: []Mod{{Slug: "jei"}, {Slug: "jei"}}, valid: false},
+		{mods: []Mod{{ID: 1}, {Slug: "jei", ID: 1}}, valid: false},
+	}
+	for _, c := range cases {
+		err := (&Manifest{Mods: c.mods}).Validate()
+		if (err == nil) != c.valid {
+			t.Errorf("mods %v: expected valid %t, got %v", c.mods, c.valid, err)
+		}
+	}
+}
+
+func TestSideIncludes(t *testing.T) {
+	var cases = []struct {
+		mod, install Side
+		included     bool
+	}{
+		{mod: "", install: SideServer, included: true},
+		{mod: SideBoth, install: SideClient, included: true},
+		{mod: SideClient, install: SideBoth, included: true},
+		{mod: SideClient, install: SideClient, included: true},
+		{mod: SideClient, install: SideServer, included: false},
+	}
+	for _, c := range cases {
+		if c.mod.Includes(c.install) != c.included {
+			t.Errorf("expected %q includes %q to be %t", c.mod, c.install, c.included)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package minecraft
+
+import (
+	"fmt"
+	"strings"
+)
+
+// snapshotCycles lists the first weekly snapshot of each development cycle,
+// and the release that the cycle led up to, in order. Weekly snapshots aren't
+// numbered, so this is how they are ordered against releases
+var snapshotCycles = []struct {
+	year, week          int
+	major, minor, patch int
+}{
+	{11, 47, 1, 1, -1},
+	{12, 1, 1, 2, -1},
+	{12, 15, 1, 3, -1},
+	{12, 32, 1, 4, -1},
+	{13, 1, 1, 5, -1},
+	{13, 16, 1, 6, -1},
+	{13, 36, 1, 7, -1},
+	{14, 2, 1, 8, -1},
+	{15, 31, 1, 9, -1},
+	{16, 20, 1, 10, -1},
+	{16, 32, 1, 11, -1},
+	{17, 6, 1, 12, -1},
+	{17, 43, 1, 13, -1},
+	{18, 43, 1, 14, -1},
+	{19, 34, 1, 15, -1},
+	{20, 6, 1, 16, -1},
+	{20, 45, 1, 17, -1},
+	{21, 37, 1, 18, -1},
+	{22, 11, 1, 19, -1},
+	{22, 42, 1, 19, 3},
+	{23, 3, 1, 19, 4},
+	{23, 12, 1, 20, -1},
+	{23, 31, 1, 20, 2},
+	{23, 40, 1, 20, 3},
+	{24, 3, 1, 20, 5},
+	{24, 18, 1, 21, -1},
+	{24, 33, 1, 21, 2},
+	{24, 44, 1, 21, 4},
+	{25, 2, 1, 21, 5},
+	{25, 15, 1, 21, 6},
+	{25, 31, 1, 21, 9},
+	{25, 41, 1, 21, 11},
+}
+
+// phase returns the phase of the version, treating an unset phase as a release
+func (v Version) phase() Phase {
+	if v.Release == "" {
+		return Release
+	}
+	return v.Release
+}
+
+// era orders the phases that came before the first full release: infdev,
+// indev, alpha then beta. Every later version shares the same era
+func (v Version) era() int {
+	switch v.phase() {
+	case Infdev:
+		return 0
+	case Indev:
+		return 1
+	case Alpha:
+		return 2
+	case Beta:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// stage orders the versions that share the same number, from the first
+// snapshots for it through to the release and any combat tests based on it
+func (v Version) stage() int {
+	switch v.phase() {
+	case Snapshot:
+		return 0
+	case PreRelease:
+		return 1
+	case ReleaseCandidate:
+		return 2
+	case CombatTest:
+		return 4
+	default:
+		return 3
+	}
+}
+
+// number returns the major, minor, patch and build numbers of the version,
+// with any missing parts as zero. Weekly snapshots take the number of the
+// release that they led up to
+func (v Version) number() [4]int {
+	if v.phase() == Snapshot && v.Year > 0 {
+		cycle := snapshotCycles[0]
+		for _, c := range snapshotCycles {
+			if c.year > v.Year || (c.year == v.Year && c.week > v.Week) {
+				break
+			}
+			cycle = c
+		}
+		return [4]int{cycle.major, cycle.minor, max0(cycle.patch), 0}
+	}
+	return [4]int{max0(v.Major), max0(v.Minor), max0(v.Patch), max0(v.Build)}
+}
+
+func max0(n int) int {
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// Compare returns less than, equal to or greater than zero as v is less than,
+// equal to or greater than o. Versions are ordered by when they were released:
+//
+//   - infdev, indev, alpha and beta versions come before any release
+//   - versions are then ordered by their number, where missing parts are zero
+//   - a version's snapshots come before its pre-releases, release candidates,
+//     then the release itself, followed by any combat tests based on it
+//   - weekly snapshots come before the release that they led up to
+//
+// Versions that are only distinguished by missing parts, such as 1.16 and
+// 1.16.0, are ordered with the missing part first. So Compare only returns zero
+// for versions that are Equal
+func (v Version) Compare(o Version) int {
+	if cmp := v.era() - o.era(); cmp != 0 {
+		return cmp
+	}
+	vNum, oNum := v.number(), o.number()
+	for idx := range vNum {
+		if cmp := vNum[idx] - oNum[idx]; cmp != 0 {
+			return cmp
+		}
+	}
+	if cmp := v.stage() - o.stage(); cmp != 0 {
+		return cmp
+	}
+
+	parts := [][2]int{
+		{v.Pre, o.Pre},
+		{v.Year, o.Year},
+		{v.Week, o.Week},
+		// Break any remaining ties with the parts as they were given
+		{v.Major, o.Major},
+		{v.Minor, o.Minor},
+		{v.Patch, o.Patch},
+		{v.Build, o.Build},
+	}
+	for _, part := range parts {
+		if cmp := part[0] - part[1]; cmp != 0 {
+			return cmp
+		}
+	}
+	if cmp := strings.Compare(v.Iteration, o.Iteration); cmp != 0 {
+		return cmp
+	}
+	return strings.Compare(string(v.phase()), string(o.phase()))
+}
+
+// Less returns true if v comes strictly before o
+func (v Version) Less(o Version) bool {
+	return v.Compare(o) < 0
+}
+
+// LessThan returns true if v comes strictly before o
+//
+// Deprecated: use Less instead
+func (v Version) LessThan(o Version) bool {
+	return v.Less(o)
+}
+
+// GreaterThan returns true if v comes strictly after o
+//
+// Deprecated: use Less, as o.Less(v), instead
+func (v Version) GreaterThan(o Version) bool {
+	return o.Less(v)
+}
+
+// Equal returns true if v and o are the same version. An unset phase is the
+// same as a release
+func (v Version) Equal(o Version) bool {
+	return v.Compare(o) == 0
+}
+
+// minorKey identifies the major and minor version, such as "1.16" or
+// "Beta 1.7", for grouping versions
+func (v Version) minorKey() string {
+	num := v.number()
+	key := fmt.Sprintf("%d.%d", num[0], num[1])
+	switch v.phase() {
+	case Indev, Infdev, Alpha, Beta:
+		return strings.Title(string(v.phase())) + " " + key
+	}
+	return key
+}
@@ -0,0 +1,186 @@
+package minecraft
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+	"testing/quick"
+)
+
+// orderedVersions are in the order that they were released
+var orderedVersions = []string{
+	"Infdev 0.31",
+	"Indev 0.31",
+	"Alpha 1.0.17_04",
+	"Alpha 1.2.6",
+	"Beta 1.5_01",
+	"Beta 1.8.1",
+	"1.0",
+	"11w47a",
+	"1.1",
+	"1.7.10",
+	"1.14-pre5",
+	"1.14",
+	"1.14.3",
+	"1.14.3 - Combat Test",
+	"1.14.4",
+	"1.15_combat-6",
+	"1.15.2",
+	"1.16-Snapshot",
+	"20w06a",
+	"20w14a",
+	"20w14~",
+	"1.16-pre1",
+	"1.16-pre8",
+	"1.16-rc1",
+	"1.16",
+	"1.16.0",
+	"1.16.5-rc1",
+	"1.16.5",
+	"20w45a",
+	"1.17",
+	"1.20.10",
+}
+
+func TestCompareOrder(t *testing.T) {
+	versions := make(Versions, len(orderedVersions))
+	for idx, str := range orderedVersions {
+		versions[idx] = *MustParse(str)
+	}
+	for i, a := range versions {
+		for j, b := range versions {
+			cmp := a.Compare(b)
+			switch {
+			case i < j && (cmp >= 0 || !a.Less(b) || a.Equal(b)):
+				t.Errorf("expected %s < %s, got %d", a, b, cmp)
+			case i > j && (cmp <= 0 || a.Less(b) || a.Equal(b)):
+				t.Errorf("expected %s > %s, got %d", a, b, cmp)
+			case i == j && (cmp != 0 || a.Less(b) || !a.Equal(b)):
+				t.Errorf("expected %s == %s, got %d", a, b, cmp)
+			}
+		}
+	}
+
+	// Sorting any permutation gives the same order
+	shuffled := append(Versions(nil), versions...)
+	rand.New(rand.NewSource(1)).Shuffle(len(shuffled), shuffled.Swap)
+	sort.Sort(shuffled)
+	if !reflect.DeepEqual(shuffled.Strings(), versions.Strings()) {
+		t.Errorf("unexpected sorted versions: %v", shuffled.Strings())
+	}
+}
+
+var testPhases = []Phase{"", Release, Alpha, Beta, Indev, Infdev, Snapshot, PreRelease, ReleaseCandidate, CombatTest}
+
+// Generate creates random versions from a small range of values, so
+// that the properties are often tested with versions that are close
+func (Version) Generate(rand *rand.Rand, size int) reflect.Value {
+	part := func() int { return rand.Intn(4) - 1 }
+	v := Version{
+		Major:   part(),
+		Minor:   part(),
+		Patch:   part(),
+		Build:   part(),
+		Pre:     part(),
+		Release: testPhases[rand.Intn(len(testPhases))],
+	}
+	if v.Release == Snapshot && rand.Intn(2) == 0 {
+		v.Year = 19 + rand.Intn(3)
+		v.Week = 1 + rand.Intn(52)
+		v.Iteration = string(rune('a' + rand.Intn(3)))
+	}
+	return reflect.ValueOf(v)
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+func TestCompareProperties(t *testing.T) {
+	config := &quick.Config{MaxCount: 20000}
+	normalise := func(v Version) Version {
+		if v.Release == "" {
+			v.Release = Release
+		}
+		return v
+	}
+
+	reflexive := func(a Version) bool {
+		return a.Compare(a) == 0 && a.Equal(a) && !a.Less(a)
+	}
+	antisymmetric := func(a, b Version) bool {
+		return sign(a.Compare(b)) == -sign(b.Compare(a)) &&
+			a.Less(b) == (a.Compare(b) < 0) && a.Equal(b) == b.Equal(a) &&
+			a.LessThan(b) == b.GreaterThan(a)
+	}
+	// Only identical versions are equal
+	equality := func(a, b Version) bool {
+		return a.Equal(b) == (normalise(a) == normalise(b))
+	}
+	transitive := func(a, b, c Version) bool {
+		if a.Compare(b) <= 0 && b.Compare(c) <= 0 {
+			return a.Compare(c) <= 0
+		}
+		if a.Compare(b) >= 0 && b.Compare(c) >= 0 {
+			return a.Compare(c) >= 0
+		}
+		return true
+	}
+	for name, prop := range map[string]interface{}{
+		"reflexive":     reflexive,
+		"antisymmetric": antisymmetric,
+		"equality":      equality,
+		"transitive":    transitive,
+	} {
+		if err := quick.Check(prop, config); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestVersionsHelpers(t *testing.T) {
+	var versions Versions
+	for _, str := range []string{"1.16.4", "1.15.2", "1.16.5", "1.16.1", "20w06a", "1.15.2", "Beta 1.7.3", "1.16-pre1"} {
+		versions = append(versions, *MustParse(str))
+	}
+
+	if max := versions.Max(); max == nil || max.String() != "1.16.5" {
+		t.Errorf("unexpected max version: %v", max)
+	}
+	if max := (Versions{}).Max(); max != nil {
+		t.Errorf("expected no max version, got %s", max)
+	}
+
+	deduped := versions.Dedupe().Strings()
+	expected := []string{"1.16.4", "1.15.2", "1.16.5", "1.16.1", "20w06a", "Beta 1.7.3", "1.16-pre1"}
+	if !reflect.DeepEqual(deduped, expected) {
+		t.Errorf("unexpected deduped versions: %v", deduped)
+	}
+
+	groups := versions.GroupByMinor()
+	expectedGroups := map[string][]string{
+		"1.15":     {"1.15.2", "1.15.2"},
+		"1.16":     {"20w06a", "1.16-pre1", "1.16.1", "1.16.4", "1.16.5"},
+		"Beta 1.7": {"Beta 1.7.3"},
+	}
+	if len(groups) != len(expectedGroups) {
+		t.Errorf("unexpected groups: %v", groups)
+	}
+	for key, group := range groups {
+		if !reflect.DeepEqual(group.Strings(), expectedGroups[key]) {
+			t.Errorf("unexpected group %s: %v", key, group.Strings())
+		}
+	}
+
+	latest := versions.LatestPatches().Strings()
+	if !reflect.DeepEqual(latest, []string{"Beta 1.7.3", "1.15.2", "1.16.5"}) {
+		t.Errorf("unexpected latest patches: %v", latest)
+	}
+}
@@ -0,0 +1,201 @@
+package minecraft
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var termRegex = regexp.MustCompile(`^(>=|<=|!=|==|=|>|<)?\s*(.*)$`)
+
+// Constraint is a set of ranges of versions, such as "1.16.x",
+// ">=1.16.2 <1.17" or "1.16.4 || 1.16.5". A version matches the constraint if
+// it matches every term of any one range.
+//
+// Terms compare only the parts of a version that they give, so "1.16" and
+// "1.16.x" both match every 1.16 release, and "<=1.16" matches 1.16.5.
+// Versions from different phases, such as betas and releases, are compared in
+// the order they were released, so "<1.0" matches Beta 1.7.3. Snapshots,
+// pre-releases and the like can't be ordered by their parts, so they only
+// match terms that name them exactly
+type Constraint struct {
+	raw    string
+	ranges [][]term
+}
+
+type term struct {
+	op string
+	// any matches every version, for the "*" or "x" wildcard
+	any bool
+	ver Version
+}
+
+// ParseConstraint parses a Constraint from its textual form
+func ParseConstraint(s string) (*Constraint, error) {
+	c := &Constraint{raw: s}
+	for _, rangeStr := range strings.Split(s, "||") {
+		rangeStr = strings.TrimSpace(rangeStr)
+		if rangeStr == "" {
+			return nil, ErrInvalidConstraint{s}
+		}
+		// Some versions contain spaces, such as "Beta 1.5_01", so try the
+		// whole range as a single term before splitting it
+		if t, err := parseTerm(rangeStr); err == nil {
+			c.ranges = append(c.ranges, []term{t})
+			continue
+		}
+
+		var terms []term
+		fields := strings.FieldsFunc(rangeStr, func(r rune) bool {
+			return r == ' ' || r == ','
+		})
+		for idx := 0; idx < len(fields); idx++ {
+			field := fields[idx]
+			// Allow a space between the operator and the version
+			if termRegex.FindStringSubmatch(field)[2] == "" && idx+1 < len(fields) {
+				idx++
+				field += fields[idx]
+			}
+			t, err := parseTerm(field)
+			if err != nil {
+				return nil, ErrInvalidConstraint{s}
+			}
+			terms = append(terms, t)
+		}
+		c.ranges = append(c.ranges, terms)
+	}
+	return c, nil
+}
+
+// MustParseConstraint is like ParseConstraint, but panics if s is invalid
+func MustParseConstraint(s string) *Constraint {
+	c, err := ParseConstraint(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func parseTerm(s string) (t term, err error) {
+	parts := termRegex.FindStringSubmatch(s)
+	t.op = parts[1]
+	if t.op == "==" || t.op == "" {
+		t.op = "="
+	}
+	verStr := strings.TrimSpace(parts[2])
+	switch strings.ToLower(verStr) {
+	case "*", "x":
+		t.any = true
+		return t, nil
+	}
+
+	// Drop any trailing wildcard, as missing parts match anything anyway
+	for _, suffix := range []string{".x", ".X", ".*"} {
+		verStr = strings.TrimSuffix(verStr, suffix)
+	}
+	// A lone major version, such as from "1.x", isn't a version by itself
+	if major, err := strconv.Atoi(verStr); err == nil && major >= 0 {
+		t.ver = Version{Major: major, Minor: -1, Patch: -1, Build: -1, Pre: -1, Release: Release}
+		return t, nil
+	}
+	err = ParseInto(&t.ver, verStr)
+	return t, err
+}
+
+// Check returns true if v matches the constraint
+func (c *Constraint) Check(v Version) bool {
+	for _, terms := range c.ranges {
+		matched := true
+		for _, t := range terms {
+			if !t.check(v) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckString returns true if s is a version that matches the constraint
+func (c *Constraint) CheckString(s string) bool {
+	v, err := Parse(s)
+	return err == nil && c.Check(*v)
+}
+
+func (c *Constraint) String() string {
+	return c.raw
+}
+
+func (t term) check(v Version) bool {
+	if t.any {
+		return true
+	}
+	if !t.ver.ordered() || !v.ordered() {
+		equal := t.ver.Equal(v)
+		if t.op == "!=" {
+			return !equal
+		}
+		return equal && strings.Contains(t.op, "=")
+	}
+
+	// Only versions in the same phase share a prefix
+	var cmp int
+	if t.ver.phase() == v.phase() {
+		cmp = comparePrefix(v, t.ver)
+	} else {
+		cmp = v.Compare(t.ver)
+	}
+	switch t.op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	}
+	return false
+}
+
+// ordered returns true if the version can be ordered by its numbered parts
+func (v Version) ordered() bool {
+	switch v.Release {
+	case Snapshot, PreRelease, ReleaseCandidate, CombatTest:
+		return false
+	}
+	return true
+}
+
+// comparePrefix compares v against the parts of prefix that are given,
+// returning less than, equal to or greater than zero as v is less than, equal
+// to or greater than prefix
+func comparePrefix(v, prefix Version) int {
+	parts := [][2]int{
+		{v.Major, prefix.Major},
+		{v.Minor, prefix.Minor},
+		{v.Patch, prefix.Patch},
+		{v.Build, prefix.Build},
+	}
+	for _, part := range parts {
+		have, want := part[0], part[1]
+		if want < 0 {
+			continue
+		}
+		// Missing parts are zero, so 1.16 compares equal to 1.16.0
+		if have < 0 {
+			have = 0
+		}
+		if have != want {
+			return have - want
+		}
+	}
+	return 0
+}
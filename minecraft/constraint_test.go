@@ -0,0 +1,112 @@
+package minecraft
+
+import "testing"
+
+func TestConstraint(t *testing.T) {
+	var cases = []struct {
+		constraint string
+		matches    []string
+		excludes   []string
+	}{
+		{
+			constraint: "1.16.5",
+			matches:    []string{"1.16.5"},
+			excludes:   []string{"1.16.4", "1.16", "1.16.5-rc1"},
+		},
+		{
+			constraint: "1.16",
+			matches:    []string{"1.16", "1.16.1", "1.16.5"},
+			excludes:   []string{"1.15.2", "1.17", "1.16-pre1", "1.16-Snapshot", "20w14a"},
+		},
+		{
+			constraint: "1.16.x",
+			matches:    []string{"1.16", "1.16.5"},
+			excludes:   []string{"1.17.1"},
+		},
+		{
+			constraint: "1.x",
+			matches:    []string{"1.7.10", "1.20.10"},
+			excludes:   []string{"Beta 1.5_01", "21w03a"},
+		},
+		{
+			constraint: ">=1.16.2 <1.17",
+			matches:    []string{"1.16.2", "1.16.5"},
+			excludes:   []string{"1.16", "1.16.1", "1.17", "1.17.1", "1.17-pre1"},
+		},
+		{
+			constraint: ">= 1.16.2, <= 1.16",
+			matches:    []string{"1.16.2", "1.16.5"},
+			excludes:   []string{"1.16.1", "1.17"},
+		},
+		{
+			constraint: ">1.16",
+			matches:    []string{"1.17", "1.20.10"},
+			excludes:   []string{"1.16.5", "1.15"},
+		},
+		{
+			constraint: "1.16.4 || 1.16.5",
+			matches:    []string{"1.16.4", "1.16.5"},
+			excludes:   []string{"1.16.3", "1.16"},
+		},
+		{
+			constraint: "1.16.x !=1.16.3",
+			matches:    []string{"1.16.2", "1.16.4"},
+			excludes:   []string{"1.16.3"},
+		},
+		{
+			constraint: "1.17-Snapshot || 21w03a",
+			matches:    []string{"1.17-Snapshot", "21w03a"},
+			excludes:   []string{"1.17", "21w03b"},
+		},
+		{
+			constraint: "Beta 1.5_01",
+			matches:    []string{"Beta 1.5_01"},
+			excludes:   []string{"Beta 1.5_02", "1.5"},
+		},
+		{
+			constraint: ">=Beta 1.7",
+			matches:    []string{"Beta 1.7", "Beta 1.7.3", "1.0", "1.2.5"},
+			excludes:   []string{"Beta 1.6.6", "Alpha 1.2.6", "Infdev 0.31"},
+		},
+		{
+			constraint: "<1.0",
+			matches:    []string{"Beta 1.7.3", "Alpha 1.2.6", "Indev 0.31"},
+			excludes:   []string{"1.0", "1.2.5"},
+		},
+		{
+			constraint: "<Beta 1.8 || >=1.16",
+			matches:    []string{"Alpha 1.2.6", "Beta 1.7.3", "1.16.5"},
+			excludes:   []string{"Beta 1.8.1", "1.0", "1.12.2"},
+		},
+		{
+			constraint: "*",
+			matches:    []string{"1.16.5", "20w14~"},
+			excludes:   []string{"Forge"},
+		},
+	}
+	for _, c := range cases {
+		constraint, err := ParseConstraint(c.constraint)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		for _, ver := range c.matches {
+			if !constraint.CheckString(ver) {
+				t.Errorf("expected '%s' to match '%s'", ver, c.constraint)
+			}
+		}
+		for _, ver := range c.excludes {
+			if constraint.CheckString(ver) {
+				t.Errorf("expected '%s' not to match '%s'", ver, c.constraint)
+			}
+		}
+	}
+}
+
+func TestInvalidConstraints(t *testing.T) {
+	for _, input := range []string{"", "||", "1.16 ||", ">=", "1.16 <foo", "~1.16"} {
+		if _, err := ParseConstraint(input); err == nil {
+			t.Errorf("expected '%s' to be invalid", input)
+		}
+	}
+}
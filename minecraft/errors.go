@@ -11,3 +11,13 @@ func (e ErrInvalidVersion) Error() string {
 }
 
 var _ error = &ErrInvalidVersion{}
+
+type ErrInvalidConstraint struct {
+	Constraint string
+}
+
+func (e ErrInvalidConstraint) Error() string {
+	return fmt.Sprintf("invalid minecraft version constraint '%s'", e.Constraint)
+}
+
+var _ error = &ErrInvalidConstraint{}
@@ -0,0 +1,64 @@
+//go:build ignore
+// +build ignore
+
+// gen_manifest fetches the version manifest from Mojang and writes the
+// releases it lists into manifest_bundled.go, to be used when the manifest
+// can't be fetched. Only the id and type of each release are kept, which is
+// enough to resolve aliases and order releases
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+
+	"github.com/frebib/mcmod/minecraft"
+)
+
+type release struct {
+	ID   string
+	Type minecraft.VersionType
+}
+
+func main() {
+	manifest, err := minecraft.FetchManifest(context.Background(), nil, minecraft.ManifestURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var releases []release
+	for _, ver := range manifest.Versions {
+		if ver.Type == minecraft.TypeRelease {
+			releases = append(releases, release{ID: ver.ID, Type: ver.Type})
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	fmt.Fprint(buf, "// Code generated by gen_manifest.go; DO NOT EDIT.\n\n")
+	fmt.Fprint(buf, "package minecraft\n\n")
+	fmt.Fprint(buf, "// bundledManifest lists every release, newest first\n")
+	fmt.Fprint(buf, "const bundledManifest = `{\n")
+	fmt.Fprintf(buf, "  \"latest\": {\"release\": %q, \"snapshot\": %q},\n",
+		manifest.Latest.Release, manifest.Latest.Release)
+	fmt.Fprint(buf, "  \"versions\": [\n")
+	for idx, rel := range releases {
+		sep := ","
+		if idx == len(releases)-1 {
+			sep = ""
+		}
+		fmt.Fprintf(buf, "    {\"id\": %q, \"type\": %q}%s\n", rel.ID, rel.Type, sep)
+	}
+	fmt.Fprint(buf, "  ]\n}`\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = ioutil.WriteFile("manifest_bundled.go", src, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
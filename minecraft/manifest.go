@@ -0,0 +1,321 @@
+package minecraft
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	modlog "github.com/frebib/mcmod/log"
+	"github.com/frebib/mcmod/util"
+)
+
+//go:generate go run gen_manifest.go
+
+// ManifestURL is where Mojang publishes the manifest of every game version
+const ManifestURL = "https://piston-meta.mojang.com/mc/game/version_manifest_v2.json"
+
+// DefaultManifestTTL is how long a cached manifest is used before fetching it
+// again
+const DefaultManifestTTL = 24 * time.Hour
+
+// ManifestKey is the textual key used to identify
+// a ManifestLoader inside a context.Context object
+const ManifestKey = "minecraft-manifest"
+
+const (
+	// AliasLatest is the newest version of any type
+	AliasLatest = "latest"
+	// AliasLatestRelease is the newest release
+	AliasLatestRelease = "latest-release"
+	// AliasLatestSnapshot is the newest snapshot, or the newest release if it
+	// is newer than every snapshot
+	AliasLatestSnapshot = "latest-snapshot"
+)
+
+// wordRegex matches each word of a version or constraint that could be an
+// alias, so that an alias is never matched as part of a longer word
+var wordRegex = regexp.MustCompile(`[\w-]+`)
+
+type VersionType string
+
+const (
+	TypeRelease  = VersionType("release")
+	TypeSnapshot = VersionType("snapshot")
+	TypeOldBeta  = VersionType("old_beta")
+	TypeOldAlpha = VersionType("old_alpha")
+)
+
+// Manifest is the manifest of every game version, as published by Mojang in
+// the version_manifest_v2.json format. Versions are listed newest first
+type Manifest struct {
+	Latest   ManifestLatest    `json:"latest"`
+	Versions []ManifestVersion `json:"versions"`
+}
+
+type ManifestLatest struct {
+	Release  string `json:"release"`
+	Snapshot string `json:"snapshot"`
+}
+
+type ManifestVersion struct {
+	ID              string      `json:"id"`
+	Type            VersionType `json:"type"`
+	URL             string      `json:"url,omitempty"`
+	Time            time.Time   `json:"time"`
+	ReleaseTime     time.Time   `json:"releaseTime"`
+	SHA1            string      `json:"sha1,omitempty"`
+	ComplianceLevel int         `json:"complianceLevel"`
+}
+
+// ParseManifest reads a manifest in the version_manifest_v2.json format
+func ParseManifest(r io.Reader) (*Manifest, error) {
+	manifest := new(Manifest)
+	err := json.NewDecoder(r).Decode(manifest)
+	if err != nil {
+		return nil, err
+	}
+	if len(manifest.Versions) < 1 {
+		return nil, fmt.Errorf("version manifest lists no versions")
+	}
+	return manifest, nil
+}
+
+// LoadManifestFile reads the manifest in the file at path
+func LoadManifestFile(path string) (*Manifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseManifest(f)
+}
+
+// FetchManifest downloads the manifest from url. If client is nil then
+// http.DefaultClient is used
+func FetchManifest(ctx context.Context, client *http.Client, url string) (*Manifest, error) {
+	data, err := fetchManifest(ctx, client, url)
+	if err != nil {
+		return nil, err
+	}
+	return ParseManifest(bytes.NewReader(data))
+}
+
+func fetchManifest(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch version manifest: %s", resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+var bundled struct {
+	once     sync.Once
+	manifest *Manifest
+}
+
+// BundledManifest returns the copy of the manifest built into mcmod. It only
+// lists releases, and may be out of date
+func BundledManifest() *Manifest {
+	bundled.once.Do(func() {
+		manifest, err := ParseManifest(strings.NewReader(bundledManifest))
+		if err != nil {
+			panic(err)
+		}
+		bundled.manifest = manifest
+	})
+	return bundled.manifest
+}
+
+// index returns the position of the version in the manifest, or -1
+func (m *Manifest) index(id string) int {
+	for idx := range m.Versions {
+		if strings.EqualFold(m.Versions[idx].ID, id) {
+			return idx
+		}
+	}
+	return -1
+}
+
+// Find returns the version with the given id, or nil if there is none
+func (m *Manifest) Find(id string) *ManifestVersion {
+	idx := m.index(id)
+	if idx < 0 {
+		return nil
+	}
+	return &m.Versions[idx]
+}
+
+// Compare orders two versions by when they were released, as Version.Compare
+// does. ok is false if either version isn't in the manifest
+func (m *Manifest) Compare(a, b string) (cmp int, ok bool) {
+	aIdx, bIdx := m.index(a), m.index(b)
+	if aIdx < 0 || bIdx < 0 {
+		return 0, false
+	}
+	// Versions are listed newest first
+	return bIdx - aIdx, true
+}
+
+// Resolve returns the version id that an alias such as "latest-release"
+// refers to. Anything that isn't an alias is returned unchanged
+func (m *Manifest) Resolve(alias string) string {
+	switch strings.ToLower(alias) {
+	case AliasLatest:
+		return m.Versions[0].ID
+	case AliasLatestRelease:
+		if m.Latest.Release != "" {
+			return m.Latest.Release
+		}
+		for _, ver := range m.Versions {
+			if ver.Type == TypeRelease {
+				return ver.ID
+			}
+		}
+	case AliasLatestSnapshot:
+		if m.Latest.Snapshot != "" {
+			return m.Latest.Snapshot
+		}
+		return m.Versions[0].ID
+	}
+	return alias
+}
+
+// HasAlias returns true if s contains any version alias
+func HasAlias(s string) bool {
+	for _, word := range wordRegex.FindAllString(s, -1) {
+		switch strings.ToLower(word) {
+		case AliasLatest, AliasLatestRelease, AliasLatestSnapshot:
+			return true
+		}
+	}
+	return false
+}
+
+// ExpandAliases replaces every alias in a version or constraint, such as
+// ">=1.16 <=latest-release", with the version it refers to
+func (m *Manifest) ExpandAliases(s string) string {
+	return wordRegex.ReplaceAllStringFunc(s, m.Resolve)
+}
+
+// DefaultManifestCachePath returns the path in the user cache directory that
+// the manifest is cached at e.g. ~/.cache/mcmod/version_manifest_v2.json
+func DefaultManifestCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "mcmod", "version_manifest_v2.json"), nil
+}
+
+// ManifestLoader loads the manifest when it is first needed. If Source is set,
+// the manifest is always loaded from it. Otherwise it is fetched from
+// ManifestURL and cached at CachePath, falling back to the cached copy, then
+// the bundled copy, if it can't be fetched
+type ManifestLoader struct {
+	// Source is the path or URL to load the manifest from
+	Source    string
+	CachePath string
+	TTL       time.Duration
+	Client    *http.Client
+
+	once     sync.Once
+	manifest *Manifest
+	err      error
+}
+
+// ManifestFromContext loads the manifest using the ManifestLoader in the
+// context, or returns the bundled manifest if there is none
+func ManifestFromContext(ctx context.Context) (*Manifest, error) {
+	loader, ok := ctx.Value(ManifestKey).(*ManifestLoader)
+	if !ok {
+		return BundledManifest(), nil
+	}
+	return loader.Load(ctx)
+}
+
+// Load loads the manifest, only the first time that it is called
+func (l *ManifestLoader) Load(ctx context.Context) (*Manifest, error) {
+	l.once.Do(func() {
+		l.manifest, l.err = l.load(ctx)
+	})
+	return l.manifest, l.err
+}
+
+func (l *ManifestLoader) load(ctx context.Context) (*Manifest, error) {
+	log := modlog.FromContext(ctx)
+
+	if l.Source != "" {
+		if strings.HasPrefix(l.Source, "http://") || strings.HasPrefix(l.Source, "https://") {
+			return FetchManifest(ctx, l.Client, l.Source)
+		}
+		return LoadManifestFile(l.Source)
+	}
+	if l.CachePath == "" {
+		manifest, err := FetchManifest(ctx, l.Client, ManifestURL)
+		if err == nil {
+			return manifest, nil
+		}
+		log.WithError(err).Warn("failed to fetch version manifest")
+		log.Warn("using bundled version manifest, which may be out of date")
+		return BundledManifest(), nil
+	}
+
+	log = log.WithField("path", l.CachePath)
+	info, statErr := os.Stat(l.CachePath)
+	if statErr == nil && time.Since(info.ModTime()) < l.TTL {
+		manifest, err := LoadManifestFile(l.CachePath)
+		if err == nil {
+			return manifest, nil
+		}
+		log.WithError(err).Warn("failed to read cached version manifest")
+	}
+
+	data, err := fetchManifest(ctx, l.Client, ManifestURL)
+	var manifest *Manifest
+	if err == nil {
+		manifest, err = ParseManifest(bytes.NewReader(data))
+	}
+	if err == nil {
+		if err := l.store(data); err != nil {
+			log.WithError(err).Warn("failed to cache version manifest")
+		}
+		return manifest, nil
+	}
+
+	log.WithError(err).Warn("failed to fetch version manifest")
+	if statErr == nil {
+		manifest, err := LoadManifestFile(l.CachePath)
+		if err == nil {
+			log.Warn("using stale cached version manifest")
+			return manifest, nil
+		}
+	}
+	log.Warn("using bundled version manifest, which may be out of date")
+	return BundledManifest(), nil
+}
+
+// store writes the manifest to the cache file
+func (l *ManifestLoader) store(data []byte) error {
+	return util.WriteFileAtomic(l.CachePath, data, 0644)
+}
@@ -0,0 +1,183 @@
+package minecraft
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testManifestPath = "testdata/version_manifest_v2.json"
+
+func TestManifest(t *testing.T) {
+	manifest, err := LoadManifestFile(testManifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ver := manifest.Find("1.16.5")
+	if ver == nil || ver.Type != TypeRelease ||
+		!ver.ReleaseTime.Equal(time.Date(2021, 1, 14, 16, 5, 32, 0, time.UTC)) {
+		t.Errorf("unexpected version: %#v", ver)
+	}
+	if ver := manifest.Find("b1.7.3"); ver == nil || ver.Type != TypeOldBeta {
+		t.Errorf("unexpected version: %#v", ver)
+	}
+	if ver := manifest.Find("1.17"); ver != nil {
+		t.Errorf("expected no version, got %#v", ver)
+	}
+
+	var cases = []struct {
+		a, b string
+		cmp  int
+		ok   bool
+	}{
+		{a: "1.16.4", b: "1.16.5", cmp: -1, ok: true},
+		{a: "21w03a", b: "1.16.5", cmp: 1, ok: true},
+		{a: "1.16.5-rc1", b: "1.16.5-rc1", cmp: 0, ok: true},
+		{a: "20w51a", b: "1.16.5-rc1", cmp: -1, ok: true},
+		{a: "1.17", b: "1.16.5"},
+	}
+	for _, c := range cases {
+		cmp, ok := manifest.Compare(c.a, c.b)
+		if sign(cmp) != c.cmp || ok != c.ok {
+			t.Errorf("%s <=> %s: expected %d %t, got %d %t", c.a, c.b, c.cmp, c.ok, cmp, ok)
+		}
+	}
+}
+
+func TestManifestAliases(t *testing.T) {
+	manifest, err := LoadManifestFile(testManifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cases = []struct {
+		input    string
+		expected string
+	}{
+		{input: "latest", expected: "21w03a"},
+		{input: "latest-release", expected: "1.16.5"},
+		{input: "Latest-Snapshot", expected: "21w03a"},
+		{input: ">=1.16 <=latest-release", expected: ">=1.16 <=1.16.5"},
+		{input: "1.16.x", expected: "1.16.x"},
+		{input: "latest-foo", expected: "latest-foo"},
+		{input: "latest || latest-release", expected: "21w03a || 1.16.5"},
+	}
+	for _, c := range cases {
+		if HasAlias(c.input) != (c.input != c.expected) {
+			t.Errorf("%s: unexpected HasAlias result", c.input)
+		}
+		if expanded := manifest.ExpandAliases(c.input); expanded != c.expected {
+			t.Errorf("%s: expected %s, got %s", c.input, c.expected, expanded)
+		}
+	}
+}
+
+func TestBundledManifest(t *testing.T) {
+	manifest := BundledManifest()
+	if ver := manifest.Find("1.16.5"); ver == nil || ver.Type != TypeRelease {
+		t.Errorf("unexpected bundled version: %#v", ver)
+	}
+	// The bundled manifest only lists releases, in order
+	for idx := 1; idx < len(manifest.Versions); idx++ {
+		newer, older := manifest.Versions[idx-1], manifest.Versions[idx]
+		if newer.Type != TypeRelease || !MustParse(older.ID).Less(*MustParse(newer.ID)) {
+			t.Errorf("expected %s < %s", older.ID, newer.ID)
+		}
+	}
+	if latest := manifest.Resolve(AliasLatestRelease); latest != manifest.Versions[0].ID {
+		t.Errorf("unexpected latest release: %s", latest)
+	}
+}
+
+func TestManifestLoader(t *testing.T) {
+	data, err := ioutil.ReadFile(testManifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var requests int
+	fail := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if fail {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+
+	dir, err := ioutil.TempDir("", "mcmod-minecraft")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	ctx := context.Background()
+	cachePath := filepath.Join(dir, "version_manifest_v2.json")
+
+	// Sources are loaded from a file or URL
+	for _, source := range []string{testManifestPath, srv.URL} {
+		manifest, err := (&ManifestLoader{Source: source}).Load(ctx)
+		if err != nil || manifest.Latest.Snapshot != "21w03a" {
+			t.Errorf("%s: failed to load manifest: %v", source, err)
+		}
+	}
+	fail = true
+	if _, err := (&ManifestLoader{Source: srv.URL}).Load(ctx); err == nil {
+		t.Error("expected failing source to return an error")
+	}
+
+	// Without a cached copy, a failed fetch falls back to the bundled copy
+	for _, path := range []string{cachePath, ""} {
+		loader := &ManifestLoader{CachePath: path, TTL: time.Hour, Client: redirectClient(srv.URL)}
+		manifest, err := loader.Load(ctx)
+		if err != nil || manifest != BundledManifest() {
+			t.Errorf("cache path '%s': expected bundled manifest, got %v", path, err)
+		}
+	}
+
+	// A fetched manifest is cached
+	fail = false
+	loader := &ManifestLoader{CachePath: cachePath, TTL: time.Hour, Client: redirectClient(srv.URL)}
+	manifest, err := loader.Load(ctx)
+	if err != nil || manifest.Latest.Snapshot != "21w03a" {
+		t.Fatalf("failed to fetch manifest: %v", err)
+	}
+	if _, err := os.Stat(cachePath); err != nil {
+		t.Errorf("expected manifest to be cached: %v", err)
+	}
+
+	// A fresh cached copy is used without fetching it again, and a stale one
+	// is used if it can't be fetched
+	requests = 0
+	fail = true
+	loader = &ManifestLoader{CachePath: cachePath, TTL: time.Hour, Client: redirectClient(srv.URL)}
+	if manifest, err := loader.Load(ctx); err != nil || manifest.Latest.Snapshot != "21w03a" || requests != 0 {
+		t.Errorf("expected cached manifest, got %v after %d requests", err, requests)
+	}
+	loader = &ManifestLoader{CachePath: cachePath, Client: redirectClient(srv.URL)}
+	if manifest, err := loader.Load(ctx); err != nil || manifest.Latest.Snapshot != "21w03a" || requests != 1 {
+		t.Errorf("expected stale cached manifest, got %v after %d requests", err, requests)
+	}
+}
+
+// redirectClient sends every request to the server at url
+func redirectClient(url string) *http.Client {
+	return &http.Client{Transport: &redirectTransport{url: url}}
+}
+
+type redirectTransport struct {
+	url string
+}
+
+func (t *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	redirected, err := http.NewRequestWithContext(req.Context(), req.Method, t.url, req.Body)
+	if err != nil {
+		return nil, err
+	}
+	return http.DefaultTransport.RoundTrip(redirected)
+}
@@ -10,20 +10,29 @@ import (
 )
 
 var verRegex = regexp.MustCompile(`(?i)^(?:(?P<release>alpha|beta|indev|infdev) )?v?` +
-	`(?P<major>\d)\.(?P<minor>\d{1,2})(?:\.(?P<patch>\d{1,2}))?(?:_(?P<build>\d{2}))?$`)
+	`(?P<major>\d+)\.(?P<minor>\d{1,2})(?:\.(?P<patch>\d{1,2}))?(?:_(?P<build>\d{2}))?` +
+	`(?:(?P<phase>-pre| pre-release |-rc| release candidate |_combat-| - combat test|-snapshot)` +
+	`(?P<pre>\d+)?)?$`)
+
+// snapshotRegex matches weekly snapshots, such as 20w14a
+var snapshotRegex = regexp.MustCompile(`(?i)^(?P<year>\d{2})w(?P<week>\d{2})(?P<iteration>[a-z~])$`)
 
 type Phase string
 
 const (
-	Release = Phase("release")
-	Alpha   = Phase("alpha")
-	Beta    = Phase("beta")
-	Indev   = Phase("indev")
-	Infdev  = Phase("infdev")
+	Release          = Phase("release")
+	Alpha            = Phase("alpha")
+	Beta             = Phase("beta")
+	Indev            = Phase("indev")
+	Infdev           = Phase("infdev")
+	Snapshot         = Phase("snapshot")
+	PreRelease       = Phase("pre-release")
+	ReleaseCandidate = Phase("release-candidate")
+	CombatTest       = Phase("combat-test")
 )
 
 func ParsePhase(s string) Phase {
-	s = strings.ToLower(s)
+	s = strings.ToLower(strings.Trim(s, "-_ "))
 	switch Phase(s) {
 	case Alpha:
 		return Alpha
@@ -33,6 +42,14 @@ func ParsePhase(s string) Phase {
 		return Indev
 	case Infdev:
 		return Infdev
+	case Snapshot:
+		return Snapshot
+	case PreRelease, "pre":
+		return PreRelease
+	case ReleaseCandidate, "rc", "release candidate":
+		return ReleaseCandidate
+	case CombatTest, "combat", "combat test":
+		return CombatTest
 	default:
 		return Release
 	}
@@ -44,11 +61,25 @@ type Version struct {
 	Patch   int
 	Build   int
 	Release Phase
+	// Pre is the number of a pre-release, release candidate or combat test
+	Pre int
+	// Year, Week and Iteration identify a weekly snapshot, such as 20w14a.
+	// Snapshots without them are all of the snapshots for a version, as
+	// CurseForge tags them e.g. 1.16-Snapshot
+	Year      int
+	Week      int
+	Iteration string
 }
 
 func (v Version) String() string {
+	if v.Release == Snapshot && v.Year > 0 {
+		return fmt.Sprintf("%02dw%02d%s", v.Year, v.Week, v.Iteration)
+	}
+
 	var s string
-	if v.Release != "" && v.Release != Release {
+	switch v.Release {
+	case "", Release, Snapshot, PreRelease, ReleaseCandidate, CombatTest:
+	default:
 		s = strings.ToUpper(fmt.Sprintf("%c", v.Release[0])) + string(v.Release)[1:] + " "
 	}
 	s += fmt.Sprintf("%d", v.Major)
@@ -61,6 +92,20 @@ func (v Version) String() string {
 	if v.Build >= 0 {
 		s += fmt.Sprintf("_%02d", v.Build)
 	}
+	switch v.Release {
+	case Snapshot:
+		s += "-Snapshot"
+	case PreRelease:
+		s += fmt.Sprintf("-pre%d", v.Pre)
+	case ReleaseCandidate:
+		s += fmt.Sprintf("-rc%d", v.Pre)
+	case CombatTest:
+		if v.Pre >= 0 {
+			s += fmt.Sprintf("_combat-%d", v.Pre)
+		} else {
+			s += " - Combat Test"
+		}
+	}
 	return s
 }
 
@@ -71,7 +116,7 @@ func (vs Versions) Len() int {
 }
 
 func (vs Versions) Less(i, j int) bool {
-	return vs[i].LessThan(vs[j])
+	return vs[i].Less(vs[j])
 }
 
 func (vs Versions) Swap(i, j int) {
@@ -86,22 +131,56 @@ func (vs Versions) Strings() []string {
 	return strs
 }
 
-func (vs Versions) LatestPatches() *Versions {
-	latestFor := make(map[string]Version, 0)
+// Max returns the greatest version, or nil if there are none
+func (vs Versions) Max() *Version {
+	if len(vs) < 1 {
+		return nil
+	}
+	max := vs[0]
+	for _, ver := range vs[1:] {
+		if max.Less(ver) {
+			max = ver
+		}
+	}
+	return &max
+}
+
+// Dedupe returns the versions without any duplicates, keeping the first of
+// each in order
+func (vs Versions) Dedupe() Versions {
+	deduped := make(Versions, 0, len(vs))
+	seen := make(map[Version]bool, len(vs))
 	for _, ver := range vs {
-		sigStr := fmt.Sprintf("%d.%d", ver.Major, ver.Minor)
-		// If there is already a version for the significant, and it is greater
-		// don't promote the lower version and continue to next
-		if latest, ok := latestFor[sigStr]; ok &&
-			latest.LessThan(ver) {
-			continue
+		key := ver
+		key.Release = ver.phase()
+		if !seen[key] {
+			seen[key] = true
+			deduped = append(deduped, ver)
 		}
-		latestFor[sigStr] = ver
 	}
+	return deduped
+}
+
+// GroupByMinor groups the versions by their major and minor version, such as
+// "1.16" or "Beta 1.7", with each group sorted. Weekly snapshots are grouped
+// with the release that they led up to
+func (vs Versions) GroupByMinor() map[string]Versions {
+	groups := make(map[string]Versions)
+	for _, ver := range vs {
+		key := ver.minorKey()
+		groups[key] = append(groups[key], ver)
+	}
+	for _, group := range groups {
+		sort.Sort(group)
+	}
+	return groups
+}
 
+// LatestPatches returns the greatest version for each minor version, in order
+func (vs Versions) LatestPatches() *Versions {
 	versions := make(Versions, 0)
-	for _, ver := range latestFor {
-		versions = append(versions, ver)
+	for _, group := range vs.GroupByMinor() {
+		versions = append(versions, group[len(group)-1])
 	}
 	sort.Sort(versions)
 	return &versions
@@ -120,30 +199,6 @@ func (v *Version) UnmarshalJSON(bytes []byte) error {
 	return ParseInto(v, str)
 }
 
-func (v Version) Compare(o Version) int {
-	cmp := o.Major - v.Major
-	if cmp != 0 {
-		return cmp
-	}
-	cmp = o.Minor - v.Minor
-	if cmp != 0 {
-		return cmp
-	}
-	cmp = o.Patch - v.Patch
-	if cmp != 0 {
-		return cmp
-	}
-	return o.Build - v.Build
-}
-
-func (v Version) LessThan(o Version) bool {
-	return v.Compare(o) < 1
-}
-
-func (v Version) GreaterThan(o Version) bool {
-	return v.Compare(o) > 1
-}
-
 func MustParse(s string) *Version {
 	ver, err := Parse(s)
 	if err != nil {
@@ -162,14 +217,19 @@ func Parse(s string) (*Version, error) {
 }
 
 func ParseInto(v *Version, s string) error {
+	*v = Version{Minor: -1, Patch: -1, Build: -1, Pre: -1, Release: Release}
+	if parts := snapshotRegex.FindStringSubmatch(s); len(parts) > 0 {
+		v.Release = Snapshot
+		v.Year, _ = strconv.Atoi(parts[1])
+		v.Week, _ = strconv.Atoi(parts[2])
+		v.Iteration = strings.ToLower(parts[3])
+		return nil
+	}
+
 	parts := verRegex.FindStringSubmatch(s)
 	if len(parts) < 1 || parts[0] == "" {
 		return ErrInvalidVersion{s}
 	}
-	v.Minor = -1
-	v.Patch = -1
-	v.Build = -1
-	v.Release = Release
 	for idx, name := range verRegex.SubexpNames() {
 		// Skip the first value, it's the whole match in one
 		if name == "" || idx == 0 {
@@ -192,10 +252,37 @@ func ParseInto(v *Version, s string) error {
 			if value != "" {
 				v.Build, err = strconv.Atoi(value)
 			}
+		case "phase":
+			if value != "" {
+				v.Release = ParsePhase(value)
+			}
+		case "pre":
+			if value != "" {
+				v.Pre, err = strconv.Atoi(value)
+			}
 		}
 		if err != nil {
 			return err
 		}
 	}
+
+	// Pre-releases and release candidates are always numbered, whereas
+	// snapshot tags never are
+	switch v.Release {
+	case PreRelease, ReleaseCandidate:
+		if v.Pre < 0 {
+			return ErrInvalidVersion{s}
+		}
+	case Snapshot:
+		if v.Pre >= 0 {
+			return ErrInvalidVersion{s}
+		}
+	case CombatTest:
+		// Only the _combat-N form is numbered
+		numbered := strings.Contains(strings.ToLower(s), "_combat-")
+		if numbered != (v.Pre >= 0) {
+			return ErrInvalidVersion{s}
+		}
+	}
 	return nil
 }
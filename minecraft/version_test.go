@@ -11,28 +11,54 @@ func TestVersions(t *testing.T) {
 		normal   string
 		expected Version
 	}{
-		{input: "1.15.2", expected: Version{Release: Release, Major: 1, Minor: 15, Patch: 2, Build: -1}},
-		{input: "1.14", expected: Version{Release: Release, Major: 1, Minor: 14, Patch: -1, Build: -1}},
-		{input: "1.7.10", expected: Version{Release: Release, Major: 1, Minor: 7, Patch: 10, Build: -1}},
-		{input: "1.0_01", expected: Version{Release: Release, Major: 1, Minor: 0, Patch: -1, Build: 1}},
-		{input: "1.0", expected: Version{Release: Release, Major: 1, Minor: 0, Patch: -1, Build: -1}},
-		{input: "Beta 1.5_01", expected: Version{Release: Beta, Major: 1, Minor: 5, Patch: -1, Build: 1}},
+		{input: "1.15.2", expected: Version{Release: Release, Major: 1, Minor: 15, Patch: 2, Build: -1, Pre: -1}},
+		{input: "1.14", expected: Version{Release: Release, Major: 1, Minor: 14, Patch: -1, Build: -1, Pre: -1}},
+		{input: "1.7.10", expected: Version{Release: Release, Major: 1, Minor: 7, Patch: 10, Build: -1, Pre: -1}},
+		{input: "1.0_01", expected: Version{Release: Release, Major: 1, Minor: 0, Patch: -1, Build: 1, Pre: -1}},
+		{input: "1.0", expected: Version{Release: Release, Major: 1, Minor: 0, Patch: -1, Build: -1, Pre: -1}},
+		{input: "Beta 1.5_01", expected: Version{Release: Beta, Major: 1, Minor: 5, Patch: -1, Build: 1, Pre: -1}},
 		{
 			input:    "Alpha v1.0.17_04",
 			normal:   "Alpha 1.0.17_04",
-			expected: Version{Release: Alpha, Major: 1, Minor: 0, Patch: 17, Build: 4},
+			expected: Version{Release: Alpha, Major: 1, Minor: 0, Patch: 17, Build: 4, Pre: -1},
 		},
 		{
 			input:    "Alpha v1.1.2_01",
 			normal:   "Alpha 1.1.2_01",
-			expected: Version{Release: Alpha, Major: 1, Minor: 1, Patch: 2, Build: 1},
+			expected: Version{Release: Alpha, Major: 1, Minor: 1, Patch: 2, Build: 1, Pre: -1},
 		},
+		{input: "1.20.10", expected: Version{Release: Release, Major: 1, Minor: 20, Patch: 10, Build: -1, Pre: -1}},
+		{input: "20w14a", expected: Version{Release: Snapshot, Minor: -1, Patch: -1, Build: -1, Pre: -1,
+			Year: 20, Week: 14, Iteration: "a"}},
+		{input: "20w14~", expected: Version{Release: Snapshot, Minor: -1, Patch: -1, Build: -1, Pre: -1,
+			Year: 20, Week: 14, Iteration: "~"}},
+		{
+			input:    "09W06A",
+			normal:   "09w06a",
+			expected: Version{Release: Snapshot, Minor: -1, Patch: -1, Build: -1, Pre: -1, Year: 9, Week: 6, Iteration: "a"},
+		},
+		{input: "1.16-Snapshot", expected: Version{Release: Snapshot, Major: 1, Minor: 16, Patch: -1, Build: -1, Pre: -1}},
+		{input: "1.16-pre1", expected: Version{Release: PreRelease, Major: 1, Minor: 16, Patch: -1, Build: -1, Pre: 1}},
+		{input: "1.16.5-rc1", expected: Version{Release: ReleaseCandidate, Major: 1, Minor: 16, Patch: 5, Build: -1, Pre: 1}},
+		{
+			input:    "1.14 Pre-Release 5",
+			normal:   "1.14-pre5",
+			expected: Version{Release: PreRelease, Major: 1, Minor: 14, Patch: -1, Build: -1, Pre: 5},
+		},
+		{
+			input:    "1.16.5 Release Candidate 2",
+			normal:   "1.16.5-rc2",
+			expected: Version{Release: ReleaseCandidate, Major: 1, Minor: 16, Patch: 5, Build: -1, Pre: 2},
+		},
+		{input: "1.15_combat-6", expected: Version{Release: CombatTest, Major: 1, Minor: 15, Patch: -1, Build: -1, Pre: 6}},
+		{input: "1.14.3 - Combat Test", expected: Version{Release: CombatTest, Major: 1, Minor: 14, Patch: 3, Build: -1, Pre: -1}},
 	}
 
 	for _, c := range cases {
 		parsed, err := Parse(c.input)
 		if err != nil {
 			t.Error(err)
+			continue
 		}
 		if !reflect.DeepEqual(parsed, &c.expected) {
 			t.Errorf("unexpected parsed output: %s\nexpected: %#v\ngot:     %#v", c.input, &c.expected, parsed)
@@ -45,3 +71,14 @@ func TestVersions(t *testing.T) {
 		}
 	}
 }
+
+func TestInvalidVersions(t *testing.T) {
+	for _, input := range []string{
+		"", "1", "Forge", "1.16-pre", "1.16-rc", "1.16-Snapshot1", "1.15_combat-",
+		"1.14.3 - Combat Test 2", "20w14", "20w14ab", "22w13oneblockatatime",
+	} {
+		if ver, err := Parse(input); err == nil {
+			t.Errorf("expected '%s' to be invalid, got %#v", input, ver)
+		}
+	}
+}
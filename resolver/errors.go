@@ -0,0 +1,27 @@
+package resolver
+
+import "fmt"
+
+type ErrNoFile struct {
+	ModID  int
+	FileID int
+}
+
+func (e *ErrNoFile) Error() string {
+	if e.FileID != 0 {
+		return fmt.Sprintf("no file %d found for mod %d", e.FileID, e.ModID)
+	}
+	return fmt.Sprintf("no suitable file found for mod %d", e.ModID)
+}
+
+// ErrIncompatible is returned when two mods in an
+// install plan are marked as incompatible
+type ErrIncompatible struct {
+	Mod  *Mod
+	With *Mod
+}
+
+func (e *ErrIncompatible) Error() string {
+	return fmt.Sprintf("mod '%s' is incompatible with '%s', which is also to be installed",
+		e.Mod.Name(), e.With.Name())
+}
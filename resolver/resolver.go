@@ -0,0 +1,355 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/frebib/mcmod/api"
+	modlog "github.com/frebib/mcmod/log"
+	"github.com/sirupsen/logrus"
+)
+
+// FileLister lists the files of a mod that are suitable for installing, newest
+// first. It should apply the same filters to every mod in the dependency tree
+type FileLister func(ctx context.Context, modID int) (api.Files, error)
+
+// Resolver walks the dependency graph of a set of mods, choosing a file for
+// each mod and following the dependencies of each chosen file
+type Resolver struct {
+	Backend   api.Backend
+	ListFiles FileLister
+	// Follow decides whether a dependency should be installed. If nil, only
+	// required dependencies are followed
+	Follow func(dep api.Dependency) bool
+}
+
+// FollowRequired follows only the dependencies that a mod requires to work
+func FollowRequired(dep api.Dependency) bool {
+	return dep.Type == api.DependencyRequired
+}
+
+// FollowOptional follows both required and optional dependencies
+func FollowOptional(dep api.Dependency) bool {
+	return dep.Type == api.DependencyRequired || dep.Type == api.DependencyOptional
+}
+
+// FollowNone follows no dependencies at all
+func FollowNone(api.Dependency) bool {
+	return false
+}
+
+// Root is a mod requested directly, rather than as a dependency
+type Root struct {
+	ModID int
+	// Addon is the metadata of the mod, if already known
+	Addon *api.Addon
+	// File, if set, is installed instead of the newest file from ListFiles
+	File *api.File
+	// FileID, if set, pins the mod to the file with the given id
+	FileID int
+	// ListFiles, if set, overrides the FileLister of the Resolver for this
+	// mod only. It isn't used for any of its dependencies
+	ListFiles FileLister
+}
+
+// Mod is a single mod in the dependency graph
+type Mod struct {
+	ID    int
+	Addon *api.Addon
+	File  *api.File
+	// Dependencies are the ids of the mods that this mod depends on
+	Dependencies []int
+	// Dependents are the ids of the mods that depend on this mod
+	Dependents []int
+	// Incompatible are the ids of the mods that can't be installed alongside
+	// this mod
+	Incompatible []int
+	// Root is true for mods that were requested directly
+	Root bool
+
+	fileID    int
+	listFiles FileLister
+}
+
+// Name returns the slug of the mod, or its id if the slug is unknown
+func (m *Mod) Name() string {
+	if m.Addon != nil && m.Addon.Slug != "" {
+		return m.Addon.Slug
+	}
+	return fmt.Sprintf("%d", m.ID)
+}
+
+// Plan is the result of resolving a dependency graph
+type Plan struct {
+	// Mods lists every mod to be installed, with each mod ordered after all
+	// of its dependencies, except where they form a cycle
+	Mods []*Mod
+	// Unresolved are the ids of dependencies with no suitable file
+	Unresolved []int
+	// Cycles lists each dependency cycle, as the ids of the mods in it
+	Cycles [][]int
+}
+
+// Mod returns the mod in the plan with the given id, or nil
+func (p *Plan) Mod(id int) *Mod {
+	for _, mod := range p.Mods {
+		if mod.ID == id {
+			return mod
+		}
+	}
+	return nil
+}
+
+// Files returns the file of every mod in the plan, in install order
+func (p *Plan) Files() []*api.File {
+	files := make([]*api.File, len(p.Mods))
+	for idx, mod := range p.Mods {
+		files[idx] = mod.File
+	}
+	return files
+}
+
+// Resolve builds an install plan for the given mods and all of their
+// transitive dependencies. Each mod is resolved once, no matter how many mods
+// depend upon it. Each level of the graph is resolved concurrently
+func (r *Resolver) Resolve(ctx context.Context, roots ...Root) (*Plan, error) {
+	log := modlog.FromContext(ctx)
+
+	mods := make(map[int]*Mod)
+	order := make([]int, 0)
+	frontier := make([]*Mod, 0, len(roots))
+	for _, root := range roots {
+		if _, ok := mods[root.ModID]; ok {
+			continue
+		}
+		mod := &Mod{
+			ID:        root.ModID,
+			Addon:     root.Addon,
+			File:      root.File,
+			Root:      true,
+			fileID:    root.FileID,
+			listFiles: root.ListFiles,
+		}
+		mods[mod.ID] = mod
+		order = append(order, mod.ID)
+		frontier = append(frontier, mod)
+	}
+
+	plan := new(Plan)
+	for depth := 0; len(frontier) > 0; depth++ {
+		log.Debugf("resolving %d mods at depth %d", len(frontier), depth)
+		deps, err := r.resolveAll(ctx, frontier)
+		if err != nil {
+			return nil, err
+		}
+
+		next := make([]*Mod, 0)
+		for idx, mod := range frontier {
+			// Mods without a file can't be installed, so drop them
+			if mod.File == nil {
+				delete(mods, mod.ID)
+				plan.Unresolved = append(plan.Unresolved, mod.ID)
+				continue
+			}
+			for _, dep := range deps[idx] {
+				if dep.AddonID == 0 {
+					continue
+				}
+				depMod, ok := mods[dep.AddonID]
+				if !ok {
+					depMod = &Mod{ID: dep.AddonID}
+					mods[depMod.ID] = depMod
+					order = append(order, depMod.ID)
+					next = append(next, depMod)
+				}
+				mod.Dependencies = append(mod.Dependencies, depMod.ID)
+				depMod.Dependents = append(depMod.Dependents, mod.ID)
+			}
+		}
+		frontier = next
+	}
+
+	// Drop any edges to dependencies that couldn't be resolved
+	for _, mod := range mods {
+		mod.Dependencies = filterIDs(mod.Dependencies, mods)
+	}
+
+	plan.Mods, plan.Cycles = sortMods(mods, order)
+	for _, cycle := range plan.Cycles {
+		log.Warnf("found dependency cycle between mods %v", cycle)
+	}
+
+	// Refuse to install any mods that conflict with each other
+	for _, mod := range plan.Mods {
+		for _, id := range mod.Incompatible {
+			if other, ok := mods[id]; ok {
+				return nil, &ErrIncompatible{Mod: mod, With: other}
+			}
+		}
+	}
+	return plan, nil
+}
+
+// resolveAll chooses a file for each mod without one, and lists the
+// dependencies of every file to follow, resolving each mod concurrently
+func (r *Resolver) resolveAll(ctx context.Context, mods []*Mod) ([][]api.Dependency, error) {
+	log := modlog.FromContext(ctx)
+
+	deps := make([][]api.Dependency, len(mods))
+	errs := make([]error, len(mods))
+	wg := new(sync.WaitGroup)
+	for idx, mod := range mods {
+		wg.Add(1)
+		go func(idx int, mod *Mod) {
+			defer wg.Done()
+			// Update logger to display correct log details for the mod
+			ctx, log := modlog.SetContextLogger(ctx, log.WithField("mod", mod.Name()))
+			deps[idx], errs[idx] = r.resolve(ctx, mod)
+			if errs[idx] != nil && !mod.Root {
+				log.WithError(errs[idx]).Warn("failed to resolve dependency, skipping")
+				errs[idx] = nil
+				mod.File = nil
+			}
+		}(idx, mod)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return deps, nil
+}
+
+func (r *Resolver) resolve(ctx context.Context, mod *Mod) ([]api.Dependency, error) {
+	log := modlog.FromContext(ctx)
+
+	if mod.Addon == nil {
+		addon, err := r.Backend.AddonByID(ctx, mod.ID)
+		if err != nil {
+			log.WithError(err).Warnf("failed to lookup dependency")
+		} else if addon != nil {
+			mod.Addon = addon
+			// Add the name now that we know what it is
+			ctx, log = modlog.SetContextLogger(ctx, log.WithField("mod", mod.Name()))
+		}
+	}
+
+	if mod.File == nil && mod.fileID != 0 {
+		file, err := r.findFile(ctx, mod.ID, mod.fileID)
+		if err != nil {
+			return nil, err
+		}
+		mod.File = file
+	} else if mod.File == nil {
+		listFiles := r.ListFiles
+		if mod.listFiles != nil {
+			listFiles = mod.listFiles
+		}
+		files, err := listFiles(ctx, mod.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(files) < 1 {
+			return nil, &ErrNoFile{ModID: mod.ID}
+		}
+		// Pick the latest release
+		mod.File = &files[0]
+	}
+	log.WithField("file-id", mod.File.ID).
+		Tracef("chose '%s'", mod.File.FileName)
+
+	deps, err := r.Backend.Dependencies(ctx, mod.File)
+	if err != nil {
+		return nil, err
+	}
+	followFunc := r.Follow
+	if followFunc == nil {
+		followFunc = FollowRequired
+	}
+	follow := make([]api.Dependency, 0, len(deps))
+	for _, dep := range deps {
+		if dep.Type == api.DependencyIncompatible {
+			mod.Incompatible = append(mod.Incompatible, dep.AddonID)
+		} else if followFunc(dep) {
+			follow = append(follow, dep)
+		} else {
+			log.WithFields(logrus.Fields{"dep": dep.AddonID, "type": dep.Type.String()}).
+				Debug("not following dependency")
+		}
+	}
+	return follow, nil
+}
+
+// findFile finds a specific file of a mod, regardless of any filters
+func (r *Resolver) findFile(ctx context.Context, modID, fileID int) (*api.File, error) {
+	files, err := r.Backend.Files(ctx, modID)
+	if err != nil {
+		return nil, err
+	}
+	for idx := range files {
+		if files[idx].ID == fileID {
+			return &files[idx], nil
+		}
+	}
+	return nil, &ErrNoFile{ModID: modID, FileID: fileID}
+}
+
+func filterIDs(ids []int, mods map[int]*Mod) []int {
+	remain := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := mods[id]; ok {
+			remain = append(remain, id)
+		}
+	}
+	return remain
+}
+
+// sortMods orders the mods so that every mod follows its dependencies, by a
+// depth-first walk from each mod in the order they were discovered. Any cycles
+// found along the way are returned, and are ordered arbitrarily
+func sortMods(mods map[int]*Mod, order []int) ([]*Mod, [][]int) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[int]int, len(mods))
+	sorted := make([]*Mod, 0, len(mods))
+	cycles := make([][]int, 0)
+	path := make([]int, 0)
+
+	var visit func(id int)
+	visit = func(id int) {
+		switch state[id] {
+		case visited:
+			return
+		case visiting:
+			// The path back to this mod forms a cycle
+			for idx := len(path) - 1; idx >= 0; idx-- {
+				if path[idx] == id {
+					cycles = append(cycles, append([]int(nil), path[idx:]...))
+					break
+				}
+			}
+			return
+		}
+		state[id] = visiting
+		path = append(path, id)
+		for _, dep := range mods[id].Dependencies {
+			visit(dep)
+		}
+		path = path[:len(path)-1]
+		state[id] = visited
+		sorted = append(sorted, mods[id])
+	}
+
+	for _, id := range order {
+		if _, ok := mods[id]; ok {
+			visit(id)
+		}
+	}
+	return sorted, cycles
+}
@@ -0,0 +1,189 @@
+package resolver
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/frebib/mcmod/api"
+)
+
+// fakeBackend serves a fixed dependency graph, where each mod has a single
+// file with the same id as the mod, depending on the mods in its entry
+type fakeBackend struct {
+	api.Backend
+	deps map[int][]api.Dependency
+}
+
+func (b *fakeBackend) AddonByID(ctx context.Context, id int) (*api.Addon, error) {
+	if _, ok := b.deps[id]; !ok {
+		return nil, &api.ErrNoSuchAddon{ID: id}
+	}
+	return &api.Addon{ID: id}, nil
+}
+
+func (b *fakeBackend) Dependencies(ctx context.Context, file *api.File) ([]api.Dependency, error) {
+	return file.Dependencies, nil
+}
+
+func (b *fakeBackend) listFiles(ctx context.Context, modID int) (api.Files, error) {
+	deps, ok := b.deps[modID]
+	if !ok {
+		return nil, nil
+	}
+	return api.Files{{ID: modID, Dependencies: deps}}, nil
+}
+
+func newFakeResolver(graph map[int][]int) *Resolver {
+	backend := &fakeBackend{deps: make(map[int][]api.Dependency)}
+	for id, depIDs := range graph {
+		deps := make([]api.Dependency, len(depIDs))
+		for idx, depID := range depIDs {
+			deps[idx] = api.Dependency{AddonID: depID, Type: api.DependencyRequired}
+		}
+		backend.deps[id] = deps
+	}
+	return &Resolver{Backend: backend, ListFiles: backend.listFiles}
+}
+
+func modIDs(mods []*Mod) []int {
+	ids := make([]int, len(mods))
+	for idx, mod := range mods {
+		ids[idx] = mod.ID
+	}
+	return ids
+}
+
+func TestResolve(t *testing.T) {
+	var cases = []struct {
+		name       string
+		graph      map[int][]int
+		roots      []int
+		order      []int
+		unresolved []int
+		cycles     [][]int
+	}{
+		{
+			name:  "transitive",
+			graph: map[int][]int{1: {2}, 2: {3}, 3: {}},
+			roots: []int{1},
+			order: []int{3, 2, 1},
+		},
+		{
+			name:  "shared dependency",
+			graph: map[int][]int{1: {2, 3}, 2: {3}, 3: {}},
+			roots: []int{1},
+			order: []int{3, 2, 1},
+		},
+		{
+			name:  "multiple roots",
+			graph: map[int][]int{1: {3}, 2: {3}, 3: {}},
+			roots: []int{1, 2, 1},
+			order: []int{3, 1, 2},
+		},
+		{
+			name:       "missing dependency",
+			graph:      map[int][]int{1: {2, 4}, 2: {}},
+			roots:      []int{1},
+			order:      []int{2, 1},
+			unresolved: []int{4},
+		},
+		{
+			name:   "cycle",
+			graph:  map[int][]int{1: {2}, 2: {3}, 3: {1}},
+			roots:  []int{1},
+			order:  []int{3, 2, 1},
+			cycles: [][]int{{1, 2, 3}},
+		},
+	}
+
+	for _, c := range cases {
+		res := newFakeResolver(c.graph)
+		roots := make([]Root, len(c.roots))
+		for idx, id := range c.roots {
+			roots[idx] = Root{ModID: id}
+		}
+		plan, err := res.Resolve(context.Background(), roots...)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if order := modIDs(plan.Mods); !reflect.DeepEqual(order, c.order) {
+			t.Errorf("%s: expected order %v, got %v", c.name, c.order, order)
+		}
+		if !reflect.DeepEqual(plan.Unresolved, c.unresolved) {
+			t.Errorf("%s: expected unresolved %v, got %v", c.name, c.unresolved, plan.Unresolved)
+		}
+		if len(c.cycles) > 0 && !reflect.DeepEqual(plan.Cycles, c.cycles) {
+			t.Errorf("%s: expected cycles %v, got %v", c.name, c.cycles, plan.Cycles)
+		}
+	}
+}
+
+func TestResolveDependents(t *testing.T) {
+	res := newFakeResolver(map[int][]int{1: {3}, 2: {3}, 3: {}})
+	plan, err := res.Resolve(context.Background(), Root{ModID: 1}, Root{ModID: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deps := plan.Mod(3).Dependents; !reflect.DeepEqual(deps, []int{1, 2}) {
+		t.Errorf("expected dependents [1 2], got %v", deps)
+	}
+	if !plan.Mod(1).Root || plan.Mod(3).Root {
+		t.Error("expected only requested mods to be roots")
+	}
+}
+
+func TestResolveMissingRoot(t *testing.T) {
+	res := newFakeResolver(map[int][]int{})
+	_, err := res.Resolve(context.Background(), Root{ModID: 1})
+	if _, ok := err.(*ErrNoFile); !ok {
+		t.Errorf("expected ErrNoFile, got %v", err)
+	}
+}
+
+func TestResolveDependencyTypes(t *testing.T) {
+	res := newFakeResolver(map[int][]int{2: {}, 3: {}, 4: {}, 5: {}})
+	backend := res.Backend.(*fakeBackend)
+	backend.deps[1] = []api.Dependency{
+		{AddonID: 2, Type: api.DependencyRequired},
+		{AddonID: 3, Type: api.DependencyOptional},
+		{AddonID: 4, Type: api.DependencyEmbedded},
+		{AddonID: 5, Type: api.DependencyTool},
+	}
+
+	var cases = []struct {
+		follow func(api.Dependency) bool
+		order  []int
+	}{
+		{follow: nil, order: []int{2, 1}},
+		{follow: FollowOptional, order: []int{2, 3, 1}},
+		{follow: FollowNone, order: []int{1}},
+	}
+	for _, c := range cases {
+		res.Follow = c.follow
+		plan, err := res.Resolve(context.Background(), Root{ModID: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if order := modIDs(plan.Mods); !reflect.DeepEqual(order, c.order) {
+			t.Errorf("expected order %v, got %v", c.order, order)
+		}
+	}
+}
+
+func TestResolveIncompatible(t *testing.T) {
+	res := newFakeResolver(map[int][]int{1: {2}, 2: {}, 3: {}})
+	backend := res.Backend.(*fakeBackend)
+	backend.deps[3] = []api.Dependency{{AddonID: 2, Type: api.DependencyIncompatible}}
+
+	// Incompatible mods are fine, as long as they're not both installed
+	_, err := res.Resolve(context.Background(), Root{ModID: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = res.Resolve(context.Background(), Root{ModID: 1}, Root{ModID: 3})
+	if _, ok := err.(*ErrIncompatible); !ok {
+		t.Errorf("expected ErrIncompatible, got %v", err)
+	}
+}
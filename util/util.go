@@ -2,12 +2,17 @@ package util
 
 import (
 	"errors"
+	"io/ioutil"
+	"os"
 	"path"
+	"path/filepath"
 	"strings"
 )
 
 var (
 	ErrConflictingPaths = errors.New("conflicting output path and directory provided")
+	ErrMultipleOutputs  = errors.New("output path given for more than one file, " +
+		"use --no-dependencies or --directory instead")
 )
 
 func EllipsiseString(str string, num int) string {
@@ -41,3 +46,36 @@ func CalcFilePath(name, ovFile, ovDir string) (string, error) {
 	}
 	return path.Join(ovDir, name), nil
 }
+
+// WriteFileAtomic writes data to the file at path, creating its directory if
+// needed. The data is written to a temporary file that replaces path once it
+// is complete, so that concurrent readers never see a partially written file
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(perm)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}